@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+/*
+	runBanner is the 'banner' command: renders text in big letters with a FIGlet font.
+	usage: art banner [-f font] [--layout full|kern|smush] [-e] [-o file] text
+	-f takes a bundled font name or a path to a .flf file, with -e the banner is encoded.
+*/
+func runBanner(args []string) int {
+	var opts options
+	var bannerOpts functions.BannerOptions
+	var listFonts bool
+	fs := flag.NewFlagSet("art banner", flag.ContinueOnError)
+	fs.StringVar(&opts.inputFile, "i", "", "reads the text from `file`")
+	fs.StringVar(&opts.outputFile, "o", "", "saves the banner to `file`, printed when empty")
+	addWriteFlags(fs, &opts)
+	fs.BoolVar(&opts.encode, "e", false, "encodes the banner")
+	fs.StringVar(&bannerOpts.Font, "f", functions.DefaultFont, "`font` name or path to a .flf file")
+	fs.StringVar(&bannerOpts.Layout, "layout", functions.LayoutDefault, "overrides the font's `layout`: full, kern or smush")
+	fs.BoolVar(&listFonts, "list-fonts", false, "lists the bundled fonts")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if listFonts {
+		fmt.Println(strings.Join(functions.FontNames(), "\n"))
+		return exitOK
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	// without text or -i the text is read from stdin.
+	if opts.inputFile == "" && len(fs.Args()) == 0 {
+		opts.inputFile = functions.Stdio
+	}
+	if opts.inputFile != "" {
+		content, err := functions.ReadTxtFile(opts.inputFile, true)
+		if err != nil {
+			return fail(err)
+		}
+		text = content
+	}
+	if strings.TrimSpace(text) == "" {
+		return fail(errors.New("no text given"))
+	}
+
+	banner, err := functions.Banner(text, bannerOpts)
+	if err != nil {
+		return fail(err)
+	}
+	if opts.encode {
+		if banner, err = functions.Encode(banner, true); err != nil {
+			return fail(err)
+		}
+	}
+	if err := writeOutput(&opts, banner); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
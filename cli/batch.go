@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// file name suffixes of art files, like the files in resources/.
+const (
+	artFileSuffix		= ".art.txt"
+	encodedFileSuffix	= ".encoded.txt"
+)
+
+// batchResult is the outcome of one file of a batch.
+type batchResult struct {
+	input		string
+	output		string
+	inputSize	int
+	outputSize	int
+	err			error
+}
+
+/*
+	runBatch is the 'batch' command: encodes or decodes many files at once.
+	usage: art batch [-e] [-j workers] [--out-dir dir] [--force] [--backup] patterns...
+	- patterns are globs (quote them to keep the shell from expanding them) or directories.
+	  directories take every *.art.txt file when encoding and every *.encoded.txt file when decoding.
+	- output names follow the resources: name.art.txt <-> name.encoded.txt, next to the input or in --out-dir.
+	  existing outputs are only replaced with --force, inputs sharing an output name are refused before anything is written.
+	- files are processed by a pool of workers, a failed file doesn't stop the others.
+	- a table with every file, its sizes and the compression ratio is printed at the end.
+	exits with 1 when any file failed.
+*/
+func runBatch(args []string) int {
+	var opts options
+	var workers int
+	var outDir string
+	fs := flag.NewFlagSet("art batch", flag.ContinueOnError)
+	fs.BoolVar(&opts.encode, "e", false, "encodes the files, they are decoded otherwise")
+	fs.IntVar(&workers, "j", runtime.NumCPU(), "number of `workers` processing files in parallel")
+	fs.StringVar(&outDir, "out-dir", "", "writes the results to `dir` instead of next to the inputs")
+	addWriteFlags(fs, &opts)
+	addNormalizeFlag(fs, &opts)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if workers < 1 {
+		return fail(errors.New("-j needs at least one worker"))
+	}
+	if fs.NArg() == 0 {
+		return fail(errors.New("expected files, globs or directories"))
+	}
+
+	inputs, err := expandBatchInputs(fs.Args(), opts.encode)
+	if err != nil {
+		return fail(err)
+	}
+	if len(inputs) == 0 {
+		return fail(errors.New("no files matched"))
+	}
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			return fail(fmt.Errorf("error creating output directory: %w", err))
+		}
+	}
+
+	suffix := artFileSuffix
+	if opts.encode {
+		suffix = encodedFileSuffix
+	}
+	outputs := make([]string, len(inputs))
+	for i, input := range inputs {
+		outputs[i] = pairedOutputPath(input, outDir, suffix)
+	}
+	// the workers would race to write a shared output, the result would depend on which one finishes last.
+	if err := checkBatchOutputs(inputs, outputs); err != nil {
+		return fail(err)
+	}
+
+	results := make([]batchResult, len(inputs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = processBatchFile(&opts, inputs[i], outputs[i])
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if printBatchSummary(results) > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// expandBatchInputs resolves the globs and directories into a sorted list of files without duplicates.
+func expandBatchInputs(patterns []string, encode bool) ([]string, error) {
+	dirPattern := "*" + encodedFileSuffix
+	if encode {
+		dirPattern = "*" + artFileSuffix
+	}
+
+	seen := map[string]bool{}
+	var inputs []string
+	for _, pattern := range patterns {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			pattern = filepath.Join(pattern, dirPattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() || seen[match] {
+				continue
+			}
+			seen[match] = true
+			inputs = append(inputs, match)
+		}
+	}
+	sort.Strings(inputs)
+	return inputs, nil
+}
+
+// checkBatchOutputs returns an error naming every output that more than one input would be written to.
+func checkBatchOutputs(inputs, outputs []string) error {
+	writers := map[string][]string{}
+	var order []string
+	for i, output := range outputs {
+		key := filepath.Clean(output)
+		if abs, err := filepath.Abs(output); err == nil {
+			key = abs
+		}
+		if len(writers[key]) == 0 {
+			order = append(order, key)
+		}
+		writers[key] = append(writers[key], inputs[i])
+	}
+
+	var duplicates []string
+	for _, key := range order {
+		if len(writers[key]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s share the output %s", strings.Join(writers[key], ", "), key))
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("inputs share an output file, rename them or use separate runs:\n  %s", strings.Join(duplicates, "\n  "))
+	}
+	return nil
+}
+
+// pairedOutputPath derives the output file from the input: the art or encoded suffix of the input
+// (or its extension for other files) is replaced with suffix, in outDir when given.
+func pairedOutputPath(input, outDir, suffix string) string {
+	base := filepath.Base(input)
+	switch {
+	case strings.HasSuffix(base, artFileSuffix):
+		base = strings.TrimSuffix(base, artFileSuffix)
+	case strings.HasSuffix(base, encodedFileSuffix):
+		base = strings.TrimSuffix(base, encodedFileSuffix)
+	default:
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	dir := filepath.Dir(input)
+	if outDir != "" {
+		dir = outDir
+	}
+	return filepath.Join(dir, base+suffix)
+}
+
+// processBatchFile encodes or decodes one file, the files are written like the ones in resources/:
+// art ends with a newline, encoded art doesn't. the line endings and BOM of the input are kept unless --normalize.
+func processBatchFile(opts *options, input, output string) batchResult {
+	result := batchResult{input: input, output: output}
+	if sameFile(input, output) {
+		result.err = errors.New("the output would overwrite the input")
+		return result
+	}
+	content, format, err := functions.ReadTextFile(input)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.inputSize = len(format.Apply(content))
+
+	var converted string
+	if opts.encode {
+		converted, err = functions.Encode(content, true)
+	} else {
+		converted, err = functions.Decode(content, true)
+	}
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	if opts.normalize {
+		format = functions.NormalizedFormat
+	}
+	format.FinalNewline = !opts.encode
+	converted = format.Apply(converted)
+	if err := writeFile(opts, output, converted); err != nil {
+		result.err = err
+		return result
+	}
+	result.outputSize = len(converted)
+	return result
+}
+
+// printBatchSummary prints a table of the results and returns the number of failed files.
+func printBatchSummary(results []batchResult) int {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tOUTPUT\tSTATUS\tIN\tOUT\tRATIO")
+	failed, totalIn, totalOut := 0, 0, 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			fmt.Fprintf(tw, "%s\t%s\terror: %v\t%d\t-\t-\n", result.input, result.output, result.err, result.inputSize)
+			continue
+		}
+		totalIn += result.inputSize
+		totalOut += result.outputSize
+		fmt.Fprintf(tw, "%s\t%s\tok\t%d\t%d\t%s\n", result.input, result.output, result.inputSize, result.outputSize, ratio(result.outputSize, result.inputSize))
+	}
+	fmt.Fprintf(tw, "\t\t%d ok, %d failed\t%d\t%d\t%s\n", len(results)-failed, failed, totalIn, totalOut, ratio(totalOut, totalIn))
+	tw.Flush()
+	return failed
+}
+
+// ratio formats out/in as a percentage.
+func ratio(out, in int) string {
+	if in == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(out)*100/float64(in))
+}
+
+// sameFile reports whether both paths name the same file.
+func sameFile(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
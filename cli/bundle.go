@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+)
+
+/*
+	runPack is the 'pack' command: runs a pipeline on an art file and saves the result as a bundle.
+	usage: art pack [--pipeline steps] [key flags] [-o bundle.art] input.txt
+*/
+func runPack(args []string) int {
+	var opts options
+	fs := flag.NewFlagSet("art pack", flag.ContinueOnError)
+	fs.StringVar(&opts.inputFile, "i", "", "reads the art from `file`")
+	fs.StringVar(&opts.outputFile, "o", "", "saves the bundle to `file`, printed when empty")
+	addWriteFlags(fs, &opts)
+	fs.StringVar(&opts.pipeline, "pipeline", "encode", "`steps` applied to the art, e.g. encode,xor,base64")
+	addKeyFlags(fs, &opts)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	path, err := commandInputPath(&opts, fs.Args())
+	if err != nil {
+		return fail(err)
+	}
+	if opts.keyBytes, err = resolveKey(&opts); err != nil {
+		return fail(err)
+	}
+	pipeline, err := functions.ParsePipeline(opts.pipeline)
+	if err != nil {
+		return fail(err)
+	}
+
+	content, err := functions.ReadTxtFile(path, true)
+	if err != nil {
+		return fail(err)
+	}
+	bundle, err := functions.PackBundle(content, pipeline, functions.PipelineOptions{Key: opts.keyBytes, MultiLine: true})
+	if err != nil {
+		return fail(err)
+	}
+
+	// the payload may be binary, so nothing is added after it.
+	if err := writeRawOutput(&opts, bundle.Marshal()); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
+
+/*
+	runUnpack is the 'unpack' command: reads a bundle, checks it and reverses all of its transforms.
+	usage: art unpack [key flags] [-o output.txt] bundle.art
+*/
+func runUnpack(args []string) int {
+	var opts options
+	fs := flag.NewFlagSet("art unpack", flag.ContinueOnError)
+	fs.StringVar(&opts.inputFile, "i", "", "reads the bundle from `file`")
+	fs.StringVar(&opts.outputFile, "o", "", "saves the unpacked art to `file`, printed when empty")
+	addWriteFlags(fs, &opts)
+	addKeyFlags(fs, &opts)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	path, err := commandInputPath(&opts, fs.Args())
+	if err != nil {
+		return fail(err)
+	}
+	data, err := functions.ReadRawFile(path)
+	if err != nil {
+		return fail(err)
+	}
+	bundle, err := functions.ParseBundle(data)
+	if err != nil {
+		return fail(err)
+	}
+
+	if opts.keyBytes, err = resolveKey(&opts); err != nil {
+		return fail(err)
+	}
+	if bundle.NeedsKey() && len(opts.keyBytes) == 0 {
+		return fail(errors.New("bundle is XOR encrypted, give the key with --key-file, --key-env, --key-prompt or --key"))
+	}
+
+	result, err := bundle.Unpack(functions.PipelineOptions{Key: opts.keyBytes})
+	if err != nil {
+		return fail(err)
+	}
+	if err := writeOutput(&opts, result); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
+
+// commandInputPath returns the input file of a command, given with -i or as the only argument.
+// without either the input is read from stdin.
+func commandInputPath(opts *options, args []string) (string, error) {
+	switch {
+	case opts.inputFile == "" && len(args) == 0:
+		return functions.Stdio, nil
+	case opts.inputFile != "" && len(args) == 0:
+		return opts.inputFile, nil
+	case opts.inputFile == "" && len(args) == 1:
+		return args[0], nil
+	default:
+		return "", errors.New("expected exactly one input file")
+	}
+}
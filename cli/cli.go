@@ -0,0 +1,438 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxLineSizeEnv is the environment variable setting the longest accepted line for every command.
+const maxLineSizeEnv = "ART_MAX_LINE_SIZE"
+
+// exit codes of the commandline tool.
+const (
+	exitOK		= 0
+	exitError	= 1 // malformed input, failed operations
+	exitUsage	= 2 // invalid flags
+)
+
+// options holds the values of every commandline flag.
+type options struct {
+	multiLine	bool
+	encode		bool
+	inputFile	string
+	outputFile	string
+	force		bool // overwrites an existing output file
+	backup		bool // keeps a replaced output file as file.bak
+	normalize	bool // writes LF text with a final newline instead of keeping the layout of the input
+	textFormat	*functions.TextFormat // layout of the input file, nil when the input isn't a multiline file
+	xor			bool
+	key			string
+	keyFile		string
+	keyEnv		string
+	keyPrompt	bool
+	keyBytes	[]byte // key resolved from whichever key source was given
+	rot13		bool
+	encoding	string // cyphertext encoding for --xor
+	encrypt		bool
+	decrypt		bool
+	pipeline	string // recipe name or step list, see functions.ParsePipeline
+	invert		bool   // runs the inverse of the pipeline
+	stripANSI	bool   // removes colour escape sequences
+	animation	bool   // the input is an animation, its frames are separated by marker lines
+	transform	string // transforms applied to the decoded art, see functions.ParseTransforms
+	transforms	[]functions.Transform
+	format		string // output format: text, png or svg
+	foreground	string
+	background	string
+	padding		int
+}
+
+// newFlagSet registers the supported flags, bound to opts.
+func newFlagSet(opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("art", flag.ContinueOnError)
+	fs.BoolVar(&opts.multiLine, "m", false, "enables multiline tool")
+	fs.BoolVar(&opts.encode, "e", false, "enables encoding")
+	fs.StringVar(&opts.inputFile, "i", "", "reads input from `file`, \"-\" or no input text reads stdin")
+	fs.StringVar(&opts.outputFile, "o", "", "saves result to `file`, stdout when empty or \"-\"")
+	addWriteFlags(fs, opts)
+	addNormalizeFlag(fs, opts)
+	fs.BoolVar(&opts.xor, "xor", false, "XOR encrypt/decrypt the input")
+	addKeyFlags(fs, opts)
+	fs.BoolVar(&opts.rot13, "rot13", false, "ROT13 encrypt/decrypt the input")
+	fs.StringVar(&opts.encoding, "encoding", functions.EncodingBase64,
+		"cyphertext `encoding` for --xor: "+strings.Join(functions.Encodings, ", "))
+	fs.BoolVar(&opts.encrypt, "encrypt", false, "--xor always encrypts the input")
+	fs.BoolVar(&opts.decrypt, "decrypt", false, "--xor always decrypts the input")
+	fs.StringVar(&opts.pipeline, "pipeline", "",
+		"runs a `pipeline` of steps, e.g. encode,xor,base64 or a recipe: "+strings.Join(functions.RecipeNames(), ", "))
+	fs.BoolVar(&opts.invert, "invert", false, "runs the inverse of --pipeline")
+	fs.BoolVar(&opts.stripANSI, "strip-ansi", false, "removes colour escape sequences from the art")
+	addAnimationFlag(fs, opts)
+	fs.StringVar(&opts.transform, "transform", "",
+		"comma separated `transforms` applied to the decoded art (or before encoding): "+strings.Join(functions.TransformNames, ", "))
+	addRenderFlags(fs, opts)
+	fs.IntVar(&functions.MaxLineSize, "max-line-size", functions.MaxLineSize,
+		"longest accepted input line in `bytes`, 0 for no limit (also $"+maxLineSizeEnv+")")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage:\n  art [flags] [input text]\n  art <command> [flags]\n  art serve (starts the web interface, as does art without arguments)")
+		fmt.Fprintln(fs.Output(), "Without input text or -i the input is read from stdin.")
+		fmt.Fprintln(fs.Output(), "Commands:\n  "+strings.Join(commandNames(), ", "))
+		fmt.Fprintln(fs.Output(), "Flags:")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "Pipeline steps:\n  "+strings.Join(functions.PipelineStepNames(), ", "))
+		fmt.Fprintln(fs.Output(), "Transforms (--transform or pipeline steps):\n  "+strings.Join(functions.TransformNames, ", "))
+	}
+	return fs
+}
+
+// addKeyFlags registers the key source flags, shared by every mode using a key.
+func addKeyFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.key, "key", "", "`key` used by --xor (prefer the options below, this one shows up in shell history)")
+	fs.StringVar(&opts.keyFile, "key-file", "", "reads the key from `file`, binary keys are supported")
+	fs.StringVar(&opts.keyEnv, "key-env", "", "reads the key from the environment `variable`")
+	fs.BoolVar(&opts.keyPrompt, "key-prompt", false, "asks for the key without echoing it")
+}
+
+// addAnimationFlag registers --animation, marker lines are ordinary text without it.
+func addAnimationFlag(fs *flag.FlagSet, opts *options) {
+	fs.BoolVar(&opts.animation, "animation", false, "the input is an animation with frames separated by '"+functions.FrameMarker+" <ms>' lines")
+}
+
+// addWriteFlags registers the flags deciding what happens to an existing output file.
+func addWriteFlags(fs *flag.FlagSet, opts *options) {
+	fs.BoolVar(&opts.force, "force", false, "overwrites the output file when it exists")
+	fs.BoolVar(&opts.backup, "backup", false, "keeps a replaced output file as file"+functions.BackupSuffix)
+}
+
+// addNormalizeFlag registers --normalize, for commands keeping the line endings, final newline and BOM of their input.
+func addNormalizeFlag(fs *flag.FlagSet, opts *options) {
+	fs.BoolVar(&opts.normalize, "normalize", false, "writes LF line endings and a final newline without BOM, instead of keeping the layout of the input")
+}
+
+// commands maps the subcommand names to their entry points.
+var commands = map[string]func(args []string) int{
+	"banner":	runBanner,
+	"batch":		runBatch,
+	"compose":	runCompose,
+	"convert":	runConvert,
+	"diff":		runDiff,
+	"fmt":		runFmt,
+	"frame":	runFrame,
+	"inspect":	runInspect,
+	"lint":		runLint,
+	"pack":		runPack,
+	"play":		runPlay,
+	"repl":		runRepl,
+	"unpack":	runUnpack,
+	"verify":	runVerify,
+	"watch":		runWatch,
+}
+
+// commandNames returns the subcommand names in alphabetical order.
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseFlags parses the arguments of a subcommand, ok is false when the command should stop with code.
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+/*
+	Run is the entry point of the commandline tool.
+	- parses the flags, remaining arguments are joined back into the input text.
+	- '-i' reads the input from a file instead, without either the input is read from stdin.
+	- '--pipeline' runs a chain of steps,
+	- '--xor' or '--rot13' run the cypher, otherwise the art is decoded (or encoded with '-e').
+	- the result is printed, or saved to a file with '-o'.
+	- lines of any length are read, '--max-line-size' or $ART_MAX_LINE_SIZE set a limit.
+	subcommands like 'art unpack bundle.art' are dispatched to their own entry points.
+	returns the exit code for the process, errors (including malformed input) are printed to stderr.
+*/
+func Run(args []string) int {
+	if value, ok := os.LookupEnv(maxLineSizeEnv); ok {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return fail(fmt.Errorf("%s must be a number of bytes, 0 for no limit", maxLineSizeEnv))
+		}
+		functions.MaxLineSize = size
+	}
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			return command(args[1:])
+		}
+	}
+
+	var opts options
+	fs := newFlagSet(&opts)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if err := validateOptions(&opts); err != nil {
+		return fail(err)
+	}
+
+	input, err := readInput(&opts, fs.Args())
+	if err != nil {
+		return fail(err)
+	}
+
+	result, err := process(&opts, input)
+	if err != nil {
+		return fail(err)
+	}
+
+	if opts.format != formatText {
+		image, err := renderResult(&opts, result)
+		if err != nil {
+			return fail(err)
+		}
+		if err := writeRawOutput(&opts, image); err != nil {
+			return fail(err)
+		}
+		return exitOK
+	}
+
+	// raw cyphertext is written byte for byte, a newline would become part of it.
+	if opts.xor && opts.encoding == functions.EncodingRaw {
+		if err := writeRawOutput(&opts, []byte(result)); err != nil {
+			return fail(err)
+		}
+		return exitOK
+	}
+	if err := writeOutput(&opts, result); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
+
+// fail prints the error to stderr and returns the exit code for failures.
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	return exitError
+}
+
+// validateOptions checks flag combinations that can't work together.
+func validateOptions(opts *options) error {
+	encoding, err := functions.ParseEncoding(opts.encoding)
+	if err != nil {
+		return err
+	}
+	opts.encoding = encoding
+
+	if opts.encrypt && opts.decrypt {
+		return errors.New("--encrypt and --decrypt can't be used together")
+	}
+	if opts.xor && opts.rot13 {
+		return errors.New("--xor and --rot13 can't be used together")
+	}
+	key, err := resolveKey(opts)
+	if err != nil {
+		return err
+	}
+	opts.keyBytes = key
+	if opts.xor && len(opts.keyBytes) == 0 {
+		return errors.New("--xor requires a key, use --key-file, --key-env, --key-prompt or --key")
+	}
+	if opts.pipeline != "" && (opts.xor || opts.rot13 || opts.encode) {
+		return errors.New("--pipeline can't be combined with -e, --xor or --rot13, add them as steps instead")
+	}
+	if err := validateFormat(opts); err != nil {
+		return err
+	}
+	if opts.transform != "" && (opts.pipeline != "" || opts.xor || opts.rot13) {
+		return errors.New("--transform only works when decoding or encoding, add transforms as --pipeline steps instead")
+	}
+	if opts.transforms, err = functions.ParseTransforms(opts.transform); err != nil {
+		return err
+	}
+	if functions.MaxLineSize < 0 {
+		return errors.New("--max-line-size can't be negative")
+	}
+	if opts.invert && opts.pipeline == "" {
+		return errors.New("--invert requires --pipeline")
+	}
+	if opts.animation && (!opts.multiLine || opts.xor || opts.rot13) {
+		return errors.New("--animation requires -m and can't be combined with --xor or --rot13")
+	}
+
+	// raw bytes would garble the terminal, so they are only allowed through files and pipes.
+	if opts.xor && opts.encoding == functions.EncodingRaw {
+		if isStdio(opts.outputFile) && !opts.decrypt && isTerminal(int(os.Stdout.Fd())) {
+			return errors.New("raw encoding can't be written to a terminal, use -o or a pipe")
+		}
+		if isStdio(opts.inputFile) && !opts.encrypt && isTerminal(int(os.Stdin.Fd())) {
+			return errors.New("raw encoding can't be read from a terminal, use -i or a pipe")
+		}
+	}
+	return nil
+}
+
+// readInput returns the text to process, from the input file, the arguments or stdin.
+func readInput(opts *options, args []string) (string, error) {
+	if opts.inputFile == "" && len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+	if opts.inputFile != "" && len(args) > 0 {
+		return "", errors.New("give either -i or input text, not both")
+	}
+	if opts.inputFile == "" {
+		opts.inputFile = functions.Stdio
+	}
+	// raw cyphertext is binary, it must be read byte for byte.
+	if opts.xor && opts.encoding == functions.EncodingRaw && !opts.encrypt {
+		data, err := functions.ReadRawFile(opts.inputFile)
+		return string(data), err
+	}
+	if opts.multiLine {
+		text, format, err := functions.ReadTextFile(opts.inputFile)
+		opts.textFormat = &format
+		return text, err
+	}
+	return functions.ReadTxtFile(opts.inputFile, false)
+}
+
+// isStdio reports whether the file name stands for stdin or stdout.
+func isStdio(path string) bool {
+	return path == "" || path == functions.Stdio
+}
+
+// process runs the mode selected by the flags on the input.
+func process(opts *options, input string) (string, error) {
+	switch {
+	case opts.pipeline != "":
+		return runPipeline(opts, input)
+	case opts.xor:
+		return functions.XorifyWith(input, opts.keyBytes, opts.encoding, direction(opts))
+	case opts.rot13:
+		return functions.Rot13ify(input), nil
+	case opts.animation:
+		return processAnimation(opts, input)
+	case opts.encode:
+		if opts.stripANSI {
+			input = functions.StripANSI(input)
+		}
+		input, err := functions.ApplyTransforms(input, opts.transforms)
+		if err != nil {
+			return "", err
+		}
+		return functions.Encode(input, opts.multiLine)
+	default:
+		result, err := functions.Decode(input, opts.multiLine)
+		if err != nil {
+			return "", err
+		}
+		if opts.stripANSI {
+			result = functions.StripANSI(result)
+		}
+		return functions.ApplyTransforms(result, opts.transforms)
+	}
+}
+
+// processAnimation encodes or decodes every frame of an animation, the frame markers are kept.
+func processAnimation(opts *options, input string) (string, error) {
+	if len(opts.transforms) > 0 {
+		return "", errors.New("--transform doesn't support animations")
+	}
+	if opts.stripANSI && opts.encode {
+		input = functions.StripANSI(input)
+	}
+	convert := functions.DecodeAnimation
+	if opts.encode {
+		convert = functions.EncodeAnimation
+	}
+	animation, err := convert(input)
+	if err != nil {
+		return "", err
+	}
+	result := animation.String()
+	if opts.stripANSI && !opts.encode {
+		result = functions.StripANSI(result)
+	}
+	return result, nil
+}
+
+// runPipeline parses --pipeline, inverts it with --invert and runs it on the input.
+func runPipeline(opts *options, input string) (string, error) {
+	pipeline, err := functions.ParsePipeline(opts.pipeline)
+	if err != nil {
+		return "", err
+	}
+	if opts.invert {
+		if pipeline, err = pipeline.Inverse(); err != nil {
+			return "", err
+		}
+	}
+	return pipeline.Run(input, functions.PipelineOptions{Key: opts.keyBytes, MultiLine: opts.multiLine})
+}
+
+// direction returns the XOR direction selected by --encrypt/--decrypt.
+func direction(opts *options) string {
+	switch {
+	case opts.encrypt:
+		return functions.DirectionEncrypt
+	case opts.decrypt:
+		return functions.DirectionDecrypt
+	default:
+		return functions.DirectionAuto
+	}
+}
+
+// writeOutput saves the result to the output file, or prints it when no file (or "-") is given.
+// a result read from a multiline file keeps the line endings, final newline and BOM of that file (unless --normalize),
+// other printed results always end with exactly one newline, so art piped between commands doesn't grow.
+func writeOutput(opts *options, result string) error {
+	if opts.textFormat != nil {
+		format := *opts.textFormat
+		if opts.normalize {
+			format = functions.NormalizedFormat
+		}
+		result = format.Apply(result)
+		if !isStdio(opts.outputFile) {
+			return writeFile(opts, opts.outputFile, result)
+		}
+		return functions.WriteTxtFile(functions.Stdio, result)
+	}
+	if !isStdio(opts.outputFile) {
+		return writeFile(opts, opts.outputFile, result)
+	}
+	if !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+	return functions.WriteTxtFile(functions.Stdio, result)
+}
+
+// writeRawOutput saves data to the output file, or writes it to stdout as is.
+func writeRawOutput(opts *options, data []byte) error {
+	if !isStdio(opts.outputFile) {
+		return writeFile(opts, opts.outputFile, string(data))
+	}
+	return functions.WriteTxtFile(functions.Stdio, string(data))
+}
+
+// writeFile saves content to path, an existing file is only replaced with --force.
+func writeFile(opts *options, path, content string) error {
+	err := functions.WriteFile(path, content, functions.WriteOptions{Overwrite: opts.force, Backup: opts.backup})
+	if errors.Is(err, functions.ErrFileExists) {
+		return fmt.Errorf("%w, use --force to overwrite it", err)
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"art/functions"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// composeOperations maps the compose operations to the number of files they take, -1 for any number.
+var composeOperations = map[string]int{
+	"hconcat":	-1,
+	"vconcat":	-1,
+	"overlay":	2,
+	"tile":		1,
+}
+
+/*
+	runCompose is the 'compose' command: combines pieces of art into one.
+	usage: art compose <hconcat|vconcat|overlay|tile> [flags] files...
+	- hconcat/vconcat put the files side by side or below each other, with --spacing and --align.
+	- overlay draws the second file over the first at -x, -y, spaces are transparent unless --opaque.
+	- tile repeats the file --columns by --rows times.
+	the files can be plain or encoded, with -e the result is encoded.
+*/
+func runCompose(args []string) int {
+	names := make([]string, 0, len(composeOperations))
+	for name := range composeOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// help before the operation succeeds like the help of every other command, the flags are listed per operation.
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		fmt.Fprintf(os.Stderr, "Usage:\n  art compose <%s> [flags] files...\n'art compose <operation> -h' lists the flags.\n", strings.Join(names, "|"))
+		return exitOK
+	}
+	if len(args) == 0 || composeOperations[args[0]] == 0 {
+		return fail(fmt.Errorf("expected an operation: %s", strings.Join(names, ", ")))
+	}
+	operation := args[0]
+
+	var opts options
+	var spacing, x, y, columns, rows int
+	var align string
+	var opaque bool
+	fs := flag.NewFlagSet("art compose "+operation, flag.ContinueOnError)
+	fs.StringVar(&opts.outputFile, "o", "", "saves the result to `file`, printed when empty")
+	addWriteFlags(fs, &opts)
+	fs.BoolVar(&opts.encode, "e", false, "encodes the result")
+	fs.IntVar(&spacing, "spacing", 0, "hconcat/vconcat: `columns` or lines between the pieces")
+	fs.StringVar(&align, "align", functions.AlignStart, "hconcat/vconcat: `alignment` start (top/left), center or end (bottom/right)")
+	fs.IntVar(&x, "x", 0, "overlay: `column` of the top piece")
+	fs.IntVar(&y, "y", 0, "overlay: `row` of the top piece")
+	fs.BoolVar(&opaque, "opaque", false, "overlay: spaces of the top piece cover the piece below")
+	fs.IntVar(&columns, "columns", 2, "tile: number of `copies` side by side")
+	fs.IntVar(&rows, "rows", 1, "tile: number of `copies` below each other")
+	if code, ok := parseFlags(fs, args[1:]); !ok {
+		return code
+	}
+
+	files := fs.Args()
+	if want := composeOperations[operation]; (want > 0 && len(files) != want) || len(files) == 0 {
+		return fail(fmt.Errorf("%s expects %s", operation, composeFileCount(want)))
+	}
+	pieces := make([]string, len(files))
+	for i, file := range files {
+		art, err := readArtFile(file)
+		if err != nil {
+			return fail(err)
+		}
+		pieces[i] = art
+	}
+
+	var result string
+	var err error
+	switch operation {
+	case "hconcat":
+		result, err = functions.HConcat(pieces, spacing, align)
+	case "vconcat":
+		result, err = functions.VConcat(pieces, spacing, align)
+	case "overlay":
+		result, err = functions.Overlay(pieces[0], pieces[1], x, y, opaque)
+	case "tile":
+		result, err = functions.Tile(pieces[0], columns, rows)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	if opts.encode {
+		if result, err = functions.Encode(result, true); err != nil {
+			return fail(err)
+		}
+	}
+	if err := writeOutput(&opts, result); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
+
+// composeFileCount describes how many files an operation takes.
+func composeFileCount(want int) string {
+	switch want {
+	case -1:
+		return "one or more files"
+	case 1:
+		return "one file"
+	default:
+		return fmt.Sprintf("%d files", want)
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"art/functions"
+	"bytes"
+	"flag"
+)
+
+/*
+	runConvert is the 'convert' command: turns a PNG, JPEG or GIF image into art.
+	usage: art convert [-w width] [--ramp chars] [--dither] [--edges] [--invert] [-e] [-o file] image.png
+	with -e the art is handed to the encoder and the encoded form is written instead.
+*/
+func runConvert(args []string) int {
+	var opts options
+	convert := functions.DefaultConvertOptions()
+	fs := flag.NewFlagSet("art convert", flag.ContinueOnError)
+	fs.StringVar(&opts.inputFile, "i", "", "reads the image from `file`")
+	fs.StringVar(&opts.outputFile, "o", "", "saves the art to `file`, printed when empty")
+	addWriteFlags(fs, &opts)
+	fs.BoolVar(&opts.encode, "e", false, "encodes the art")
+	fs.IntVar(&convert.Width, "w", convert.Width, "`width` of the art in characters")
+	fs.StringVar(&convert.Ramp, "ramp", convert.Ramp, "`characters` from darkest to lightest")
+	fs.Float64Var(&convert.CellAspect, "aspect", convert.CellAspect, "character cell height divided by its width")
+	fs.BoolVar(&convert.Dither, "dither", false, "dithers the image (Floyd-Steinberg)")
+	fs.BoolVar(&convert.Edges, "edges", false, "draws strong edges with line characters")
+	fs.BoolVar(&convert.Invert, "invert", false, "inverts the ramp, for light art on dark backgrounds")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	path, err := commandInputPath(&opts, fs.Args())
+	if err != nil {
+		return fail(err)
+	}
+	data, err := functions.ReadRawFile(path)
+	if err != nil {
+		return fail(err)
+	}
+
+	art, err := functions.ConvertImage(bytes.NewReader(data), convert)
+	if err != nil {
+		return fail(err)
+	}
+	if opts.encode {
+		if art, err = functions.Encode(art, true); err != nil {
+			return fail(err)
+		}
+	}
+	if err := writeOutput(&opts, art); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// diff output styles.
+const (
+	diffOverlay	= "overlay"
+	diffUnified	= "unified"
+	diffCells	= "cells"
+)
+
+/*
+	runDiff is the 'diff' command: compares two versions of the art cell by cell.
+	usage: art diff [--style overlay|unified|cells] [--no-colour] old new
+	plain and encoded files can be mixed, encoded files are decoded first.
+	like diff(1) it exits with 0 when the art is the same, 1 when it differs and 2 on errors.
+*/
+func runDiff(args []string) int {
+	var style string
+	var noColour bool
+	fs := flag.NewFlagSet("art diff", flag.ContinueOnError)
+	fs.StringVar(&style, "style", diffOverlay, "output `style`: overlay (coloured new version), unified or cells (one line per changed cell)")
+	fs.BoolVar(&noColour, "no-colour", false, "overlay without colours, changed cells are only listed")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 2 {
+		return diffError(errors.New("expected the old and the new file"))
+	}
+	oldPath, newPath := fs.Arg(0), fs.Arg(1)
+	oldArt, err := readArtFile(oldPath)
+	if err != nil {
+		return diffError(err)
+	}
+	newArt, err := readArtFile(newPath)
+	if err != nil {
+		return diffError(err)
+	}
+
+	diff := functions.DiffArt(oldArt, newArt)
+	switch style {
+	case diffOverlay:
+		// colours only make sense on a terminal, otherwise the cells are listed.
+		if !noColour && isTerminal(int(os.Stdout.Fd())) {
+			fmt.Print(diff.Overlay())
+		} else {
+			fmt.Print(diff.CellReport())
+		}
+	case diffUnified:
+		fmt.Print(diff.Unified(oldPath, newPath))
+	case diffCells:
+		fmt.Print(diff.CellReport())
+	default:
+		return diffError(fmt.Errorf("unknown style %q, expected overlay, unified or cells", style))
+	}
+	fmt.Fprintln(os.Stderr, diff.Summary())
+
+	if diff.Equal() {
+		return 0
+	}
+	return 1
+}
+
+// diffError prints the error and returns the exit code diff uses for trouble.
+func diffError(err error) int {
+	fail(err)
+	return 2
+}
+
+// readArtFile reads a multiline art file, decoding it when it is encoded.
+func readArtFile(path string) (string, error) {
+	content, err := functions.ReadTxtFile(path, true)
+	if err != nil {
+		return "", err
+	}
+	return functions.DecodeIfEncoded(content), nil
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+	"strings"
+)
+
+/*
+	runFrame is the 'frame' command: draws a border around art or text.
+	usage: art frame [--style name] [--padding N] [--title text] [--align start|center|end] [-e] [-i file | text]
+	-i reads plain or encoded art, otherwise the arguments are framed as a line of text, or stdin without either.
+*/
+func runFrame(args []string) int {
+	var opts options
+	var frameOpts functions.FrameOptions
+	fs := flag.NewFlagSet("art frame", flag.ContinueOnError)
+	fs.StringVar(&opts.inputFile, "i", "", "reads the art from `file`, plain or encoded")
+	fs.StringVar(&opts.outputFile, "o", "", "saves the framed art to `file`, printed when empty")
+	addWriteFlags(fs, &opts)
+	fs.BoolVar(&opts.encode, "e", false, "encodes the framed art")
+	fs.StringVar(&frameOpts.Style, "style", functions.DefaultFrameStyle, "border `style`: "+strings.Join(functions.FrameStyleNames(), ", "))
+	fs.IntVar(&frameOpts.Padding, "padding", 1, "`spaces` between the border and the art")
+	fs.StringVar(&frameOpts.Title, "title", "", "`text` shown in the top border")
+	fs.StringVar(&frameOpts.Align, "align", functions.AlignStart, "`alignment` of the lines: start, center or end")
+	fs.StringVar(&frameOpts.TitleAlign, "title-align", functions.AlignStart, "`alignment` of the title: start, center or end")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	// without text or -i the art is read from stdin.
+	if opts.inputFile == "" && len(fs.Args()) == 0 {
+		opts.inputFile = functions.Stdio
+	}
+	if opts.inputFile != "" {
+		if len(fs.Args()) > 0 {
+			return fail(errors.New("give either -i or text, not both"))
+		}
+		art, err := readArtFile(opts.inputFile)
+		if err != nil {
+			return fail(err)
+		}
+		text = art
+	}
+	if text == "" {
+		return fail(errors.New("nothing to frame"))
+	}
+
+	framed, err := functions.FrameArt(text, frameOpts)
+	if err != nil {
+		return fail(err)
+	}
+	if opts.encode {
+		if framed, err = functions.Encode(framed, true); err != nil {
+			return fail(err)
+		}
+	}
+	if err := writeOutput(&opts, framed); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"art/functions"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// inspect defaults.
+const (
+	defaultInspectLimit	= 10000 // the server's MaxInputLength
+	maxExpansion		= 100   // decoded art more than this many times larger than the file is suspicious
+)
+
+/*
+	runInspect is the 'inspect' command: reports statistics of art or encoded files without decoding them.
+	usage: art inspect [--limit bytes] [--chars N] [files...]
+	- size, decoded size and compression ratio, rows, width and ragged lines.
+	- number of runs and the largest counts, a histogram of the characters and an entropy estimate.
+	files decoding to more than --limit bytes or expanding more than 100 times are flagged,
+	exits with 1 when any file was flagged or couldn't be read. stdin is read without files.
+*/
+func runInspect(args []string) int {
+	var limit int64
+	var chars int
+	fs := flag.NewFlagSet("art inspect", flag.ContinueOnError)
+	fs.Int64Var(&limit, "limit", defaultInspectLimit, "flags files decoding to more than `bytes`")
+	fs.IntVar(&chars, "chars", 10, "number of `characters` shown in the histogram")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{functions.Stdio}
+	}
+	code := exitOK
+	for i, file := range files {
+		if i > 0 {
+			fmt.Println()
+		}
+		data, err := functions.ReadRawFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, positionError(file, err))
+			code = exitError
+			continue
+		}
+		stats, err := functions.InspectArt(string(data))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, positionError(file, err))
+			code = exitError
+			continue
+		}
+		warnings := inspectWarnings(stats, limit)
+		printStats(file, stats, chars, warnings)
+		if len(warnings) > 0 {
+			code = exitError
+		}
+	}
+	return code
+}
+
+// inspectWarnings returns what makes the file suspicious.
+func inspectWarnings(stats functions.ArtStats, limit int64) []string {
+	var warnings []string
+	if stats.DecodedSize > limit {
+		warnings = append(warnings, fmt.Sprintf("decodes to %d bytes, more than the limit of %d", stats.DecodedSize, limit))
+	}
+	if stats.Size > 0 && stats.DecodedSize/int64(stats.Size) > maxExpansion {
+		warnings = append(warnings, fmt.Sprintf("expands %d times, more than %d", stats.DecodedSize/int64(stats.Size), maxExpansion))
+	}
+	return warnings
+}
+
+// printStats prints the statistics of one file as an aligned list.
+func printStats(file string, stats functions.ArtStats, chars int, warnings []string) {
+	kind := "plain art"
+	if stats.Encoded {
+		kind = "encoded art"
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "file:\t%s (%s)\n", file, kind)
+	fmt.Fprintf(tw, "size:\t%d bytes\n", stats.Size)
+	fmt.Fprintf(tw, "layout:\t%s\n", stats.Format)
+	fmt.Fprintf(tw, "decoded size:\t%d bytes\n", stats.DecodedSize)
+	fmt.Fprintf(tw, "compression:\t%.0f%% of the decoded size\n", stats.CompressionRatio()*100)
+	fmt.Fprintf(tw, "rows:\t%d\n", stats.Rows)
+	fmt.Fprintf(tw, "width:\t%d (%d ragged %s)\n", stats.Width, stats.RaggedLines, pluralize(stats.RaggedLines, "line"))
+	if stats.Encoded {
+		largest := make([]string, len(stats.LargestRuns))
+		for i, run := range stats.LargestRuns {
+			largest[i] = fmt.Sprintf("%d×%q at %d:%d", run.Count, run.Pattern, run.Line, run.Column)
+		}
+		fmt.Fprintf(tw, "runs:\t%d\n", stats.Runs)
+		if len(largest) > 0 {
+			fmt.Fprintf(tw, "largest runs:\t%s\n", strings.Join(largest, ", "))
+		}
+	}
+	fmt.Fprintf(tw, "entropy:\t%.2f bits per character\n", stats.Entropy)
+
+	histogram := make([]string, 0, chars)
+	for _, char := range stats.Histogram[:min(len(stats.Histogram), max(chars, 0))] {
+		histogram = append(histogram, fmt.Sprintf("%q %d", char.Char, char.Count))
+	}
+	if len(stats.Histogram) > len(histogram) {
+		histogram = append(histogram, fmt.Sprintf("%d more", len(stats.Histogram)-len(histogram)))
+	}
+	fmt.Fprintf(tw, "characters:\t%s\n", strings.Join(histogram, ", "))
+	for _, warning := range warnings {
+		fmt.Fprintf(tw, "warning:\t%s\n", warning)
+	}
+	tw.Flush()
+}
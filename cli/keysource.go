@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+)
+
+/*
+	resolveKey returns the key from the one key source that was given:
+	- '--key text' (visible in shell history and process lists).
+	- '--key-file path', read byte for byte so binary keys work.
+	- '--key-env NAME', read from the environment variable NAME.
+	- '--key-prompt', typed into an interactive prompt without echo.
+	returns nil when no key source was given.
+*/
+func resolveKey(opts *options) ([]byte, error) {
+	sources := 0
+	for _, given := range []bool{opts.key != "", opts.keyFile != "", opts.keyEnv != "", opts.keyPrompt} {
+		if given {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, errors.New("only one of --key, --key-file, --key-env and --key-prompt can be used")
+	}
+
+	switch {
+	case opts.key != "":
+		return []byte(opts.key), nil
+	case opts.keyFile != "":
+		return readKeyFile(opts.keyFile)
+	case opts.keyEnv != "":
+		key, ok := os.LookupEnv(opts.keyEnv)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("environment variable %s is not set or empty", opts.keyEnv)
+		}
+		return []byte(key), nil
+	case opts.keyPrompt:
+		return promptKey()
+	default:
+		return nil, nil
+	}
+}
+
+/*
+	readKeyFile reads a key file as is, so binary keys keep every byte.
+	text keys usually end with a newline added by the editor or echo,
+	a single trailing newline is dropped when the file is valid text.
+*/
+func readKeyFile(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key file: %w", err)
+	}
+	if utf8.Valid(key) {
+		text := strings.TrimSuffix(string(key), "\n")
+		key = []byte(strings.TrimSuffix(text, "\r"))
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("key file %s is empty", path)
+	}
+	return key, nil
+}
+
+/*
+	promptKey asks for the key on the controlling terminal with echo turned off.
+	the terminal is used even when stdin is redirected, so input can still be piped in.
+	Ctrl-C or a termination signal while typing restores the echo before giving up.
+*/
+func promptKey() ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		if !isTerminal(int(os.Stdin.Fd())) {
+			return nil, errors.New("--key-prompt requires a terminal")
+		}
+		tty = os.Stdin
+	} else {
+		defer tty.Close()
+	}
+
+	// catch the signals before echo is off, so there's no moment they would leave it off.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	fmt.Fprint(os.Stderr, "Key: ")
+	restore, err := disableEcho(int(tty.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("can't hide the key while typing: %w", err)
+	}
+
+	type result struct {
+		line	string
+		err		error
+	}
+	read := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(tty).ReadString('\n')
+		read <- result{line, err}
+	}()
+
+	var line string
+	select {
+	case r := <-read:
+		line, err = r.line, r.err
+	case <-signals:
+		restore()
+		fmt.Fprintln(os.Stderr)
+		return nil, errors.New("key prompt interrupted")
+	}
+	restore()
+	fmt.Fprintln(os.Stderr)
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("error reading key: %w", err)
+	}
+
+	key := strings.TrimRight(line, "\r\n")
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+	return []byte(key), nil
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+/*
+	runLint is the 'lint' command: reports encoded constructs that could be written shorter.
+	usage: art lint [files...]
+	issues are printed as file:line:column: message, stdin is read without files.
+	exits with 1 when any file has issues or is malformed.
+*/
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("art lint", flag.ContinueOnError)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{functions.Stdio}
+	}
+	code := exitOK
+	for _, file := range files {
+		content, _, err := functions.ReadTextFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, positionError(file, err))
+			code = exitError
+			continue
+		}
+		issues, err := functions.Lint(content)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, positionError(file, err))
+			code = exitError
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Printf("%s:%d:%d: %s\n", file, issue.Line, issue.Column, issue.Msg)
+			code = exitError
+		}
+	}
+	return code
+}
+
+/*
+	runFmt is the 'fmt' command: rewrites encoded files in their canonical minimal form.
+	usage: art fmt [-w [--backup]] [-l] [--normalize] [files...]
+	- the canonical form is printed, -w rewrites the files instead (atomically, --backup keeps the originals).
+	- -l lists the files that aren't in canonical form.
+	the decoded art never changes, the line endings, final newline and BOM of a file are kept unless --normalize.
+	stdin is read without files.
+*/
+func runFmt(args []string) int {
+	var opts options
+	var write, list, backup bool
+	fs := flag.NewFlagSet("art fmt", flag.ContinueOnError)
+	fs.BoolVar(&write, "w", false, "rewrites the files instead of printing them")
+	fs.BoolVar(&list, "l", false, "lists the files that aren't in canonical form")
+	fs.BoolVar(&backup, "backup", false, "-w keeps the original files as file"+functions.BackupSuffix)
+	addNormalizeFlag(fs, &opts)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		if write {
+			return fail(errors.New("-w needs files to rewrite"))
+		}
+		files = []string{functions.Stdio}
+	}
+	code := exitOK
+	for _, file := range files {
+		content, format, err := functions.ReadTextFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, positionError(file, err))
+			code = exitError
+			continue
+		}
+		canonical, err := functions.Canonical(content)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, positionError(file, err))
+			code = exitError
+			continue
+		}
+		original := format.Apply(content)
+		if opts.normalize {
+			format = functions.NormalizedFormat
+		}
+		canonical = format.Apply(canonical)
+
+		changed := canonical != original
+		if list && changed {
+			fmt.Println(file)
+		}
+		switch {
+		case write && file != functions.Stdio:
+			if !changed {
+				continue
+			}
+			if err := functions.WriteFile(file, canonical, functions.WriteOptions{Overwrite: true, Backup: backup}); err != nil {
+				fmt.Fprintln(os.Stderr, positionError(file, err))
+				code = exitError
+			}
+		case !list:
+			if err := functions.WriteTxtFile(functions.Stdio, canonical); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	return code
+}
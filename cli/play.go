@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"art/functions"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// terminal control sequences used by the player.
+const (
+	hideCursor	= "\x1b[?25l"
+	showCursor	= "\x1b[?25h"
+	clearBelow	= "\x1b[J"
+)
+
+/*
+	runPlay is the 'play' command: plays an animation in the terminal, or exports it as a GIF with -o.
+	usage: art play [--art] [--loop N] [--speed X] [-o file.gif] file
+	the file holds encoded frames separated by '@frame <ms>' lines, --art for frames that are already decoded.
+*/
+func runPlay(args []string) int {
+	var opts options
+	var plainArt bool
+	var loops int
+	var speed float64
+	fs := flag.NewFlagSet("art play", flag.ContinueOnError)
+	fs.StringVar(&opts.inputFile, "i", "", "reads the animation from `file`")
+	fs.StringVar(&opts.outputFile, "o", "", "exports the animation as a GIF to `file` instead of playing it")
+	addWriteFlags(fs, &opts)
+	fs.BoolVar(&plainArt, "art", false, "the frames are decoded art instead of encoded")
+	fs.IntVar(&loops, "loop", 1, "plays the animation `N` times, 0 plays it until interrupted")
+	fs.Float64Var(&speed, "speed", 1, "playback speed `factor`")
+	fs.StringVar(&opts.foreground, "fg", "#f1f5f9", "text `colour` of the GIF")
+	fs.StringVar(&opts.background, "bg", "#121212", "background `colour` of the GIF")
+	fs.IntVar(&opts.padding, "padding", functions.DefaultRenderOptions().Padding, "space around the GIF frames in `pixels`")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	path, err := commandInputPath(&opts, fs.Args())
+	if err != nil {
+		return fail(err)
+	}
+	if loops < 0 || speed <= 0 {
+		return fail(errors.New("--loop can't be negative and --speed must be positive"))
+	}
+	text, err := functions.ReadTxtFile(path, true)
+	if err != nil {
+		return fail(err)
+	}
+
+	var animation functions.Animation
+	if plainArt {
+		animation, err = functions.SplitFrames(text)
+	} else {
+		animation, err = functions.DecodeAnimation(text)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	if opts.outputFile != "" {
+		image, err := renderGIF(&opts, animation)
+		if err != nil {
+			return fail(err)
+		}
+		if err := writeRawOutput(&opts, image); err != nil {
+			return fail(err)
+		}
+		return exitOK
+	}
+
+	if !isTerminal(int(os.Stdout.Fd())) {
+		return fail(errors.New("play needs a terminal, use -o to export a GIF"))
+	}
+	// Ctrl-C stops the player, the cursor is restored before exiting.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	play(ctx, animation, loops, speed)
+	return exitOK
+}
+
+// play draws the frames over each other until every loop is done or ctx is cancelled.
+func play(ctx context.Context, animation functions.Animation, loops int, speed float64) {
+	fmt.Print(hideCursor)
+	defer fmt.Print(showCursor)
+
+	height := 0
+	for loop := 0; loops == 0 || loop < loops; loop++ {
+		for _, frame := range animation.Frames {
+			// move back to the first line of the previous frame and clear it.
+			if height > 0 {
+				fmt.Printf("\x1b[%dF%s", height, clearBelow)
+			}
+			art := strings.TrimSuffix(frame.Art, "\n")
+			fmt.Println(art)
+			height = strings.Count(art, "\n") + 1
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Duration(float64(frame.Delay) / speed)):
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"art/functions"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// output formats of the commandline tool.
+const (
+	formatText	= "text"
+	formatPNG	= "png"
+	formatSVG	= "svg"
+	formatGIF	= "gif"
+)
+
+// addRenderFlags registers the output format flags.
+func addRenderFlags(fs *flag.FlagSet, opts *options) {
+	defaults := functions.DefaultRenderOptions()
+	fs.StringVar(&opts.format, "format", formatText, "output `format`: text, png, svg or gif (renders the resulting art, gif plays animations)")
+	fs.StringVar(&opts.foreground, "fg", "#f1f5f9", "text `colour` for png/svg")
+	fs.StringVar(&opts.background, "bg", "#121212", "background `colour` for png/svg")
+	fs.IntVar(&opts.padding, "padding", defaults.Padding, "space around the art in `pixels` for png/svg")
+}
+
+// validateFormat checks the output format flags.
+func validateFormat(opts *options) error {
+	switch opts.format {
+	case formatText:
+		return nil
+	case formatPNG, formatSVG, formatGIF:
+	default:
+		return fmt.Errorf("unsupported format %q, expected text, png, svg or gif", opts.format)
+	}
+	if opts.encode || opts.xor || opts.rot13 {
+		return errors.New("image output is only available when decoding or running a pipeline")
+	}
+	if opts.format != formatSVG && opts.outputFile == "" && isTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("%s output can't be printed to a terminal, use -o", opts.format)
+	}
+	if opts.padding < 0 {
+		return errors.New("--padding can't be negative")
+	}
+	return nil
+}
+
+// renderOptions builds the render options from the colour and padding flags.
+func renderOptions(opts *options) (functions.RenderOptions, error) {
+	render := functions.DefaultRenderOptions()
+	var err error
+	if render.Foreground, err = functions.ParseColor(opts.foreground); err != nil {
+		return render, err
+	}
+	if render.Background, err = functions.ParseColor(opts.background); err != nil {
+		return render, err
+	}
+	render.Padding = opts.padding
+	return render, nil
+}
+
+// renderResult draws the art in the selected image format.
+func renderResult(opts *options, art string) ([]byte, error) {
+	render, err := renderOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch opts.format {
+	case formatPNG:
+		err = functions.RenderPNG(&buf, art, render)
+	case formatGIF:
+		// still art becomes a single frame GIF.
+		animation := functions.Animation{Frames: []functions.Frame{{Art: art, Delay: functions.DefaultFrameDelay}}}
+		if opts.animation {
+			if animation, err = functions.SplitFrames(art); err != nil {
+				return nil, err
+			}
+		}
+		return renderGIF(opts, animation)
+	default:
+		err = functions.RenderSVG(&buf, art, render)
+	}
+	return buf.Bytes(), err
+}
+
+// renderGIF draws the decoded animation as an animated GIF.
+func renderGIF(opts *options, animation functions.Animation) ([]byte, error) {
+	render, err := renderOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = functions.WriteGIF(&buf, animation, render)
+	return buf.Bytes(), err
+}
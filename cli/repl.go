@@ -0,0 +1,434 @@
+package cli
+
+import (
+	"art/functions"
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// repl modes, Tab cycles through them in this order.
+var replModes = []string{"decode", "encode", "xor", "rot13"}
+
+// repl limits.
+const (
+	maxReplHistory		= 20      // like the history of the web interface
+	maxPreviewLines		= 15      // lines of the live preview
+	maxReplDecodedSize	= 1 << 20 // larger results are refused instead of filling the terminal
+	maxPreviewInput		= 1000    // longer lines are only evaluated on Enter, not on every key press
+	escapeTimeout		= 100 * time.Millisecond // wait for the rest of an escape sequence after Escape
+)
+
+// terminal sequences of the live preview.
+const (
+	dimText		= "\x1b[2m"
+	resetText	= "\x1b[0m"
+)
+
+// replEntry is one evaluated line, like a HistoryEntry of the web interface.
+type replEntry struct {
+	Timestamp	string
+	Action		string
+	Input		string
+	Result		string
+}
+
+// repl is an interactive session.
+type repl struct {
+	mode	string
+	key		[]byte // XOR key, set with :key or the key flags
+	history	[]replEntry // newest first
+	last	string // last result, saved with :save
+	out		io.Writer
+	fd		int // terminal read in raw mode, for the escape sequence timeout
+}
+
+/*
+	runRepl is the 'repl' command: an interactive session for quick experiments.
+	usage: art repl [--mode decode|encode|xor|rot13] [key flags] [--plain]
+	- every line is run through the current mode and its result printed, Tab cycles the modes.
+	- on a terminal the result of the line being typed is previewed live below the prompt.
+	- commands start with ':', see :help. the session history mirrors the one of the web interface.
+	dumb terminals (TERM=dumb), pipes and --plain get line by line input without the live preview.
+*/
+func runRepl(args []string) int {
+	var opts options
+	var plain bool
+	r := &repl{out: os.Stdout}
+	fs := flag.NewFlagSet("art repl", flag.ContinueOnError)
+	fs.StringVar(&r.mode, "mode", "decode", "starting `mode`: "+strings.Join(replModes, ", "))
+	fs.BoolVar(&plain, "plain", false, "reads whole lines without the live preview, like on a dumb terminal")
+	addKeyFlags(fs, &opts)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if !isReplMode(r.mode) {
+		return fail(fmt.Errorf("unknown mode %q, expected %s", r.mode, strings.Join(replModes, ", ")))
+	}
+	key, err := resolveKey(&opts)
+	if err != nil {
+		return fail(err)
+	}
+	r.key = key
+
+	stdin := int(os.Stdin.Fd())
+	if !plain && os.Getenv("TERM") != "dumb" && isTerminal(stdin) && isTerminal(int(os.Stdout.Fd())) {
+		if restore, err := enableRawMode(stdin); err == nil {
+			defer restore()
+			r.fd = stdin
+			fmt.Fprint(r.out, "art repl, :help for commands, Tab switches the mode, Ctrl-D quits\n")
+			r.runRaw(bufio.NewReader(os.Stdin))
+			return exitOK
+		}
+	}
+	if isTerminal(stdin) {
+		fmt.Fprint(r.out, "art repl, :help for commands, Ctrl-D quits\n")
+	}
+	r.runLines(bufio.NewReader(os.Stdin), isTerminal(stdin))
+	return exitOK
+}
+
+// isReplMode reports whether mode is one of replModes.
+func isReplMode(mode string) bool {
+	for _, name := range replModes {
+		if name == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// prompt shows the current mode.
+func (r *repl) prompt() string {
+	return r.mode + "> "
+}
+
+// runLines is the fallback for dumb terminals and pipes: whole lines are read and answered.
+func (r *repl) runLines(in *bufio.Reader, interactive bool) {
+	for {
+		if interactive {
+			fmt.Fprint(r.out, r.prompt())
+		}
+		line, err := in.ReadString('\n')
+		if line == "" && err != nil {
+			return
+		}
+		if !r.handle(strings.TrimRight(line, "\r\n")) {
+			return
+		}
+	}
+}
+
+/*
+	runRaw reads key presses and redraws the prompt with a live preview after every one of them:
+	- Enter runs the line, Tab cycles the mode, Up/Down recall earlier lines, Left/Right move the cursor.
+	- Backspace deletes, Ctrl-U clears the line, Ctrl-C clears the line or quits on an empty one, Ctrl-D quits on an empty line.
+	- a lone Escape does nothing, keys already waiting (e.g. pasted text) are taken before the preview is redrawn.
+*/
+func (r *repl) runRaw(in *bufio.Reader) {
+	var line []rune
+	cursor := 0
+	recall := -1 // index into history while browsing with Up/Down
+	redraw := func() {
+		r.drawPrompt(line, cursor)
+	}
+	redraw()
+
+	for {
+		key, err := in.ReadByte()
+		if err != nil {
+			return
+		}
+		switch key {
+		case '\r', '\n':
+			r.finishLine(line)
+			input := string(line)
+			line, cursor, recall = nil, 0, -1
+			if !r.handle(input) {
+				return
+			}
+		case '\t':
+			r.mode = replModes[(replModeIndex(r.mode)+1)%len(replModes)]
+		case 3: // Ctrl-C
+			if len(line) == 0 {
+				r.finishLine(line)
+				return
+			}
+			line, cursor = nil, 0
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				r.finishLine(line)
+				return
+			}
+		case 21: // Ctrl-U
+			line, cursor = nil, 0
+		case 127, 8: // Backspace
+			if cursor > 0 {
+				line = append(line[:cursor-1], line[cursor:]...)
+				cursor--
+			}
+		case 27: // escape sequences of the arrow keys
+			sequence := r.readEscape(in)
+			switch sequence {
+			case "[A": // Up
+				if recall+1 < len(r.history) {
+					recall++
+					line = []rune(r.history[recall].Input)
+					cursor = len(line)
+				}
+			case "[B": // Down
+				if recall > 0 {
+					recall--
+					line = []rune(r.history[recall].Input)
+				} else if recall == 0 {
+					recall, line = -1, nil
+				}
+				cursor = len(line)
+			case "[C": // Right
+				cursor = min(cursor+1, len(line))
+			case "[D": // Left
+				cursor = max(cursor-1, 0)
+			}
+		default:
+			if key < 32 {
+				continue
+			}
+			char, err := readRune(in, key)
+			if err != nil {
+				return
+			}
+			line = append(line[:cursor], append([]rune{char}, line[cursor:]...)...)
+			cursor++
+		}
+		if in.Buffered() == 0 {
+			redraw()
+		}
+	}
+}
+
+// replModeIndex returns the position of mode in replModes.
+func replModeIndex(mode string) int {
+	for i, name := range replModes {
+		if name == mode {
+			return i
+		}
+	}
+	return 0
+}
+
+/*
+	readEscape reads the rest of an escape sequence like "[A", a lone Escape returns "".
+	the rest is waited for escapeTimeout at most, so a lone Escape doesn't hold back the next key,
+	a key that doesn't continue a sequence is left for the next read.
+*/
+func (r *repl) readEscape(in *bufio.Reader) string {
+	restore, err := setReadTimeout(r.fd, escapeTimeout)
+	if err != nil {
+		return ""
+	}
+	defer restore()
+
+	first, err := in.ReadByte()
+	if err != nil {
+		return ""
+	}
+	if first != '[' && first != 'O' {
+		in.UnreadByte()
+		return ""
+	}
+	second, err := in.ReadByte()
+	if err != nil {
+		return ""
+	}
+	return "[" + string(second)
+}
+
+// readRune completes the UTF-8 character starting with first.
+func readRune(in *bufio.Reader, first byte) (rune, error) {
+	buf := []byte{first}
+	for !utf8.FullRune(buf) {
+		next, err := in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		buf = append(buf, next)
+	}
+	char, _ := utf8.DecodeRune(buf)
+	return char, nil
+}
+
+// drawPrompt redraws the prompt line with the preview below it and puts the cursor back on the prompt line.
+func (r *repl) drawPrompt(line []rune, cursor int) {
+	prompt := r.prompt()
+	fmt.Fprintf(r.out, "\r%s%s%s", clearBelow, prompt, string(line))
+
+	if preview := r.preview(string(line)); preview != "" {
+		lines := strings.Split(preview, "\n")
+		if len(lines) > maxPreviewLines {
+			lines = append(lines[:maxPreviewLines], fmt.Sprintf("... %d more lines", len(lines)-maxPreviewLines))
+		}
+		for _, previewLine := range lines {
+			fmt.Fprintf(r.out, "\n%s", previewLine)
+		}
+		fmt.Fprintf(r.out, "\x1b[%dA", len(lines))
+	}
+	fmt.Fprintf(r.out, "\r\x1b[%dC", utf8.RuneCountInString(prompt)+cursor)
+}
+
+// finishLine removes the preview and leaves the prompt line as typed.
+func (r *repl) finishLine(line []rune) {
+	fmt.Fprintf(r.out, "\r%s%s%s\n", clearBelow, r.prompt(), string(line))
+}
+
+// preview returns the result of the line being typed, or the reason there is none, dimmed.
+func (r *repl) preview(input string) string {
+	if input == "" || strings.HasPrefix(input, ":") {
+		return ""
+	}
+	if len(input) > maxPreviewInput {
+		return dimText + fmt.Sprintf("no preview for lines over %d bytes, Enter runs the line", maxPreviewInput) + resetText
+	}
+	result, err := r.evaluate(input)
+	if err != nil {
+		return dimText + err.Error() + resetText
+	}
+	return result
+}
+
+// handle runs a line or a command, returns false when the session should end.
+func (r *repl) handle(input string) bool {
+	if strings.HasPrefix(input, ":") {
+		return r.command(input)
+	}
+	if strings.TrimSpace(input) == "" {
+		return true
+	}
+	result, err := r.evaluate(input)
+	if err != nil {
+		fmt.Fprintln(r.out, "Error:", err)
+		return true
+	}
+	fmt.Fprintln(r.out, result)
+	r.last = result
+	r.history = append([]replEntry{{
+		Timestamp:	time.Now().Format("January 2, 15:04"),
+		Action:		r.mode,
+		Input:		input,
+		Result:		result,
+	}}, r.history...)
+	if len(r.history) > maxReplHistory {
+		r.history = r.history[:maxReplHistory]
+	}
+	return true
+}
+
+// evaluate runs the input through the current mode.
+func (r *repl) evaluate(input string) (string, error) {
+	switch r.mode {
+	case "encode":
+		return functions.Encode(input, false)
+	case "xor":
+		if len(r.key) == 0 {
+			return "", errors.New("no key, set one with :key")
+		}
+		return functions.XorifyWith(input, r.key, functions.EncodingBase64, functions.DirectionAuto)
+	case "rot13":
+		return functions.Rot13ify(input), nil
+	default:
+		// the size is known without decoding, so '[999999999 x]' can't fill the memory.
+		stats, err := functions.InspectArt(input)
+		if err != nil {
+			return "", err
+		}
+		if stats.DecodedSize > maxReplDecodedSize {
+			return "", fmt.Errorf("decodes to %d bytes, more than %d", stats.DecodedSize, maxReplDecodedSize)
+		}
+		return functions.Decode(input, false)
+	}
+}
+
+// command runs a ':' command, returns false for :quit.
+func (r *repl) command(input string) bool {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(input, ":"), " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "q", "quit", "exit":
+		return false
+	case "help", "h":
+		fmt.Fprint(r.out, replHelp)
+	case "mode", "m":
+		if arg == "" {
+			fmt.Fprintln(r.out, r.mode)
+		} else if isReplMode(arg) {
+			r.mode = arg
+		} else {
+			fmt.Fprintf(r.out, "Error: unknown mode %q, expected %s\n", arg, strings.Join(replModes, ", "))
+		}
+	case "decode", "encode", "xor", "rot13":
+		r.mode = name
+		if name == "xor" && arg != "" {
+			r.key = []byte(arg)
+		}
+	case "key":
+		if arg == "" {
+			fmt.Fprintln(r.out, "Error: :key needs a key")
+		} else {
+			r.key = []byte(arg)
+		}
+	case "history":
+		r.printHistory()
+	case "save", "save!":
+		r.save(arg, name == "save!")
+	default:
+		fmt.Fprintf(r.out, "Error: unknown command :%s, see :help\n", name)
+	}
+	return true
+}
+
+// replHelp lists the commands of the repl.
+const replHelp = `  [5 #]              runs the line through the current mode
+  :decode :encode     switches the mode (Tab cycles decode, encode, xor, rot13)
+  :xor [key] :rot13   switches to a cypher, :key sets the XOR key
+  :history            lists the lines of this session
+  :save file          saves the last result, :save! replaces an existing file
+  :quit               ends the session (Ctrl-D)
+`
+
+// printHistory lists the session history, newest first.
+func (r *repl) printHistory() {
+	if len(r.history) == 0 {
+		fmt.Fprintln(r.out, "no history yet")
+		return
+	}
+	for _, entry := range r.history {
+		fmt.Fprintf(r.out, "%s  %-6s  %s\n", entry.Timestamp, entry.Action, entry.Input)
+	}
+}
+
+// save writes the last result to file.
+func (r *repl) save(file string, overwrite bool) {
+	if file == "" {
+		fmt.Fprintln(r.out, "Error: :save needs a file name")
+		return
+	}
+	if r.last == "" {
+		fmt.Fprintln(r.out, "Error: nothing to save yet")
+		return
+	}
+	err := functions.WriteFile(file, r.last+"\n", functions.WriteOptions{Overwrite: overwrite})
+	if errors.Is(err, functions.ErrFileExists) {
+		fmt.Fprintf(r.out, "Error: %v, use :save! to replace it\n", err)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(r.out, "Error:", err)
+		return
+	}
+	fmt.Fprintf(r.out, "saved to %s\n", file)
+}
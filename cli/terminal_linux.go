@@ -0,0 +1,11 @@
+//go:build linux
+
+package cli
+
+import "syscall"
+
+// ioctl requests for reading and writing the terminal settings.
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
@@ -0,0 +1,31 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package cli
+
+import (
+	"errors"
+	"time"
+)
+
+// errNoTerminalSupport is returned where terminal settings can't be changed.
+var errNoTerminalSupport = errors.New("terminal control is not supported on this platform")
+
+// isTerminal can't detect terminals on this platform and always reports false.
+func isTerminal(fd int) bool {
+	return false
+}
+
+// disableEcho is not supported on this platform.
+func disableEcho(fd int) (func(), error) {
+	return nil, errNoTerminalSupport
+}
+
+// enableRawMode is not supported on this platform, callers fall back to line input.
+func enableRawMode(fd int) (func(), error) {
+	return nil, errNoTerminalSupport
+}
+
+// setReadTimeout is not supported on this platform.
+func setReadTimeout(fd int, timeout time.Duration) (func(), error) {
+	return nil, errNoTerminalSupport
+}
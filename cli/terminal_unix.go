@@ -0,0 +1,84 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package cli
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// getTermios reads the terminal settings of fd, it fails when fd is not a terminal.
+func getTermios(fd int) (*syscall.Termios, error) {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(ioctlGetTermios), uintptr(unsafe.Pointer(&termios)))
+	if errno != 0 {
+		return nil, errno
+	}
+	return &termios, nil
+}
+
+// setTermios applies the terminal settings to fd.
+func setTermios(fd int, termios *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(ioctlSetTermios), uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// isTerminal reports whether fd is connected to a terminal.
+func isTerminal(fd int) bool {
+	_, err := getTermios(fd)
+	return err == nil
+}
+
+// disableEcho stops the terminal from echoing typed characters,
+// the returned function restores the previous settings.
+func disableEcho(fd int) (func(), error) {
+	old, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+	noEcho := *old
+	noEcho.Lflag &^= syscall.ECHO
+	noEcho.Lflag |= syscall.ICANON | syscall.ISIG
+	if err := setTermios(fd, &noEcho); err != nil {
+		return nil, err
+	}
+	return func() { setTermios(fd, old) }, nil
+}
+
+// enableRawMode hands every key press to the program right away, without echo or signals,
+// output processing stays on so "\n" still starts a new line. the returned function restores the previous settings.
+func enableRawMode(fd int) (func(), error) {
+	old, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+	raw := *old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := setTermios(fd, &raw); err != nil {
+		return nil, err
+	}
+	return func() { setTermios(fd, old) }, nil
+}
+
+// setReadTimeout makes reads of fd return after timeout (in tenths of a second) even when no key was pressed,
+// the returned function restores the previous settings.
+func setReadTimeout(fd int, timeout time.Duration) (func(), error) {
+	old, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+	timed := *old
+	timed.Cc[syscall.VMIN] = 0
+	timed.Cc[syscall.VTIME] = uint8(max(timeout/(100*time.Millisecond), 1))
+	if err := setTermios(fd, &timed); err != nil {
+		return nil, err
+	}
+	return func() { setTermios(fd, old) }, nil
+}
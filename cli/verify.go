@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"art/functions"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxReportedMismatches limits the mismatched lines printed per check.
+const maxReportedMismatches = 10
+
+// artPair is a plain art file and its encoded version.
+type artPair struct {
+	art		string
+	encoded	string
+}
+
+/*
+	runVerify is the 'verify' command: checks that encoded files decode to their art and that the art survives a round trip.
+	usage: art verify [--strict] [art-file encoded-file | directories...]
+	- two files are verified as a pair, directories pair their name.art.txt and name.encoded.txt files.
+	- without arguments the pairs in resources/ are verified.
+	- for every pair the encoded file must decode to the art exactly, and encoding then decoding the art must give it back.
+	- --strict also requires the encoded file to be exactly what the encoder produces.
+	mismatches are reported by line and column, exits with 1 when any pair failed so it can run in CI.
+*/
+func runVerify(args []string) int {
+	var strict bool
+	fs := flag.NewFlagSet("art verify", flag.ContinueOnError)
+	fs.BoolVar(&strict, "strict", false, "the encoded files must be exactly what the encoder produces")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"resources"}
+	}
+	pairs, err := collectPairs(paths)
+	if err != nil {
+		return fail(err)
+	}
+	if len(pairs) == 0 {
+		return fail(errors.New("no art files found"))
+	}
+
+	failed := 0
+	for _, pair := range pairs {
+		problems := verifyPair(pair, strict)
+		if len(problems) == 0 {
+			fmt.Printf("ok    %s <-> %s\n", pair.art, pair.encoded)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s <-> %s\n", displayPath(pair.art), displayPath(pair.encoded))
+		for _, problem := range problems {
+			fmt.Printf("      %s\n", problem)
+		}
+	}
+	fmt.Printf("%d %s verified, %d failed\n", len(pairs), pluralize(len(pairs), "pair"), failed)
+	if failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// collectPairs returns the pairs given as two files or found in directories, a file without its partner is a pair with a missing side.
+func collectPairs(paths []string) ([]artPair, error) {
+	if len(paths) == 2 {
+		first, errFirst := os.Stat(paths[0])
+		second, errSecond := os.Stat(paths[1])
+		if errFirst == nil && errSecond == nil && !first.IsDir() && !second.IsDir() {
+			return []artPair{{art: paths[0], encoded: paths[1]}}, nil
+		}
+	}
+
+	var pairs []artPair
+	for _, dir := range paths {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory, give two files or directories", dir)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory: %w", err)
+		}
+		names := map[string]bool{}
+		for _, entry := range entries {
+			names[entry.Name()] = true
+		}
+		seen := map[string]bool{}
+		for _, entry := range entries {
+			name := entry.Name()
+			var base string
+			switch {
+			case strings.HasSuffix(name, artFileSuffix):
+				base = strings.TrimSuffix(name, artFileSuffix)
+			case strings.HasSuffix(name, encodedFileSuffix):
+				base = strings.TrimSuffix(name, encodedFileSuffix)
+			default:
+				continue
+			}
+			if seen[base] {
+				continue
+			}
+			seen[base] = true
+			pair := artPair{}
+			if names[base+artFileSuffix] {
+				pair.art = filepath.Join(dir, base+artFileSuffix)
+			}
+			if names[base+encodedFileSuffix] {
+				pair.encoded = filepath.Join(dir, base+encodedFileSuffix)
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairKey(pairs[i]) < pairKey(pairs[j])
+	})
+	return pairs, nil
+}
+
+// pairKey is the name a pair is sorted by, whichever side exists.
+func pairKey(pair artPair) string {
+	if pair.art != "" {
+		return pair.art
+	}
+	return pair.encoded
+}
+
+// displayPath shows a missing side of a pair.
+func displayPath(path string) string {
+	if path == "" {
+		return "(missing)"
+	}
+	return path
+}
+
+// verifyPair runs the checks on one pair and returns the problems found.
+func verifyPair(pair artPair, strict bool) []string {
+	if pair.art == "" || pair.encoded == "" {
+		return []string{"the pair is incomplete, both name" + artFileSuffix + " and name" + encodedFileSuffix + " are needed"}
+	}
+	art, err := readPairFile(pair.art)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	encoded, err := readPairFile(pair.encoded)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	if decoded, err := functions.Decode(encoded, true); err != nil {
+		problems = append(problems, "decode: "+err.Error())
+	} else {
+		problems = append(problems, mismatchReport("decode", functions.CompareText(art, decoded))...)
+	}
+
+	if mismatches, err := functions.VerifyRoundTrip(art); err != nil {
+		problems = append(problems, "round trip: "+err.Error())
+	} else {
+		problems = append(problems, mismatchReport("round trip", mismatches)...)
+	}
+
+	if strict {
+		// a failed encode was already reported by the round trip.
+		if reencoded, err := functions.Encode(art, true); err == nil {
+			problems = append(problems, mismatchReport("encode", functions.CompareText(encoded, reencoded))...)
+		}
+	}
+	return problems
+}
+
+// readPairFile reads one side of a pair, the final newline of the file is not part of the art.
+func readPairFile(path string) (string, error) {
+	content, err := functions.ReadTxtFile(path, true)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(content, "\n"), nil
+}
+
+// mismatchReport formats the mismatches of a check, at most maxReportedMismatches of them.
+func mismatchReport(check string, mismatches []functions.Mismatch) []string {
+	var lines []string
+	for i, mismatch := range mismatches {
+		if i == maxReportedMismatches {
+			lines = append(lines, fmt.Sprintf("%s: ... and %d more %s", check, len(mismatches)-i, pluralize(len(mismatches)-i, "line")))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", check, mismatch))
+	}
+	return lines
+}
+
+// pluralize adds an 's' to word unless n is 1.
+func pluralize(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"art/functions"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchedFile is the last seen state of a watched file.
+type watchedFile struct {
+	modTime	time.Time
+	size	int64
+	changed	time.Time // when the last change was seen
+	pending	bool      // changed but not converted yet
+}
+
+// watcher polls the watched paths and converts the files that changed.
+type watcher struct {
+	opts	*options
+	paths	[]string
+	match	string // file pattern used inside watched directories
+	suffix	string // suffix of the paired output files
+	outDir	string
+	files	map[string]*watchedFile
+}
+
+/*
+	runWatch is the 'watch' command: converts files again whenever they are saved.
+	usage: art watch [-e | --xor | --rot13 | --pipeline steps] [--animation] [--interval d] [--debounce d] [-o file | --out-dir dir] paths...
+	- paths are files, globs or directories, directories are polled for files matching --match.
+	- the files are polled every --interval, so no platform specific APIs are needed.
+	- a file is converted once it hasn't changed for --debounce, so editors saving in bursts trigger one run.
+	- the output is the paired file (name.art.txt <-> name.encoded.txt), other modes need --suffix or -o.
+	- malformed input is reported as file:line:column and the previous output is kept.
+	runs until interrupted with Ctrl-C.
+*/
+func runWatch(args []string) int {
+	var opts options
+	var interval, debounce time.Duration
+	w := &watcher{opts: &opts, files: map[string]*watchedFile{}}
+	fs := flag.NewFlagSet("art watch", flag.ContinueOnError)
+	fs.BoolVar(&opts.encode, "e", false, "encodes the files, they are decoded otherwise")
+	fs.BoolVar(&opts.xor, "xor", false, "XOR encrypts/decrypts the files")
+	addKeyFlags(fs, &opts)
+	fs.StringVar(&opts.encoding, "encoding", functions.EncodingBase64,
+		"cyphertext `encoding` for --xor: "+strings.Join(functions.Encodings, ", ")+" (except raw)")
+	fs.BoolVar(&opts.encrypt, "encrypt", false, "--xor always encrypts the files")
+	fs.BoolVar(&opts.decrypt, "decrypt", false, "--xor always decrypts the files")
+	fs.BoolVar(&opts.rot13, "rot13", false, "ROT13 encrypts/decrypts the files")
+	fs.StringVar(&opts.pipeline, "pipeline", "", "runs a `pipeline` of steps or a recipe on the files")
+	fs.BoolVar(&opts.invert, "invert", false, "runs the inverse of --pipeline")
+	addAnimationFlag(fs, &opts)
+	fs.StringVar(&opts.outputFile, "o", "", "writes the result to `file`, only when watching one file")
+	addNormalizeFlag(fs, &opts)
+	fs.StringVar(&w.outDir, "out-dir", "", "writes the results to `dir` instead of next to the inputs")
+	fs.StringVar(&w.suffix, "suffix", "", "`suffix` of the output files, .art.txt when decoding and .encoded.txt when encoding")
+	fs.StringVar(&w.match, "match", "", "`pattern` of the files watched in directories, *.encoded.txt when decoding and *.art.txt when encoding")
+	fs.DurationVar(&interval, "interval", 500*time.Millisecond, "how often the files are polled")
+	fs.DurationVar(&debounce, "debounce", 300*time.Millisecond, "how long a file must stay unchanged before it is converted")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if err := w.setup(fs.Args(), interval, debounce); err != nil {
+		return fail(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	w.run(ctx, interval, debounce)
+	return exitOK
+}
+
+// setup validates the flags and fills in the defaults of the mode.
+func (w *watcher) setup(paths []string, interval, debounce time.Duration) error {
+	opts := w.opts
+	if len(paths) == 0 {
+		return errors.New("expected files, globs or directories to watch")
+	}
+	if interval <= 0 || debounce < 0 {
+		return errors.New("--interval must be positive and --debounce can't be negative")
+	}
+	encoding, err := functions.ParseEncoding(opts.encoding)
+	if err != nil {
+		return err
+	}
+	if opts.xor && encoding == functions.EncodingRaw {
+		return errors.New("watch doesn't support the raw encoding, the files are read as text")
+	}
+	opts.multiLine = true
+	opts.format = formatText
+	if err := validateOptions(opts); err != nil {
+		return err
+	}
+	if opts.encode && (opts.xor || opts.rot13) {
+		return errors.New("-e can't be combined with --xor or --rot13")
+	}
+
+	if opts.outputFile != "" && (w.outDir != "" || w.suffix != "") {
+		return errors.New("-o can't be combined with --out-dir or --suffix")
+	}
+	cypher := opts.xor || opts.rot13 || opts.pipeline != ""
+	if w.suffix == "" && !cypher && opts.outputFile == "" {
+		w.suffix = artFileSuffix
+		if opts.encode {
+			w.suffix = encodedFileSuffix
+		}
+	}
+	if w.match == "" {
+		w.match = "*" + encodedFileSuffix
+		if opts.encode {
+			w.match = "*" + artFileSuffix
+		} else if cypher {
+			w.match = "*.txt"
+		}
+	}
+	if _, err := filepath.Match(w.match, ""); err != nil {
+		return fmt.Errorf("invalid --match pattern %q: %w", w.match, err)
+	}
+
+	if opts.outputFile != "" {
+		if len(paths) != 1 || strings.ContainsAny(paths[0], "*?[") {
+			return errors.New("-o needs exactly one file to watch")
+		}
+		if info, err := os.Stat(paths[0]); err == nil && info.IsDir() {
+			return errors.New("-o needs a file, not a directory")
+		}
+	} else if w.suffix == "" {
+		return errors.New("--xor, --rot13 and --pipeline need --suffix or -o to name the output files")
+	}
+	if w.outDir != "" {
+		if err := os.MkdirAll(w.outDir, 0o755); err != nil {
+			return fmt.Errorf("error creating output directory: %w", err)
+		}
+	}
+	w.paths = paths
+	return nil
+}
+
+// run polls the files until ctx is cancelled. on start, files with a missing or older output are converted.
+func (w *watcher) run(ctx context.Context, interval, debounce time.Duration) {
+	for path, info := range w.scan() {
+		w.files[path] = &watchedFile{modTime: info.ModTime(), size: info.Size()}
+		if output, err := os.Stat(w.outputPath(path)); err != nil || output.ModTime().Before(info.ModTime()) {
+			w.convert(path)
+		}
+	}
+	fmt.Printf("watching %d file(s), press Ctrl-C to stop\n", len(w.files))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.poll(now, debounce)
+		}
+	}
+}
+
+// poll records the changes since the last poll and converts the files that settled for debounce.
+func (w *watcher) poll(now time.Time, debounce time.Duration) {
+	current := w.scan()
+	for path := range w.files {
+		if _, ok := current[path]; !ok {
+			delete(w.files, path)
+		}
+	}
+	for path, info := range current {
+		file, ok := w.files[path]
+		if !ok {
+			file = &watchedFile{}
+			w.files[path] = file
+		}
+		if !ok || !info.ModTime().Equal(file.modTime) || info.Size() != file.size {
+			file.modTime, file.size = info.ModTime(), info.Size()
+			file.changed, file.pending = now, true
+		}
+		if file.pending && now.Sub(file.changed) >= debounce {
+			file.pending = false
+			w.convert(path)
+		}
+	}
+}
+
+// scan returns the watched files, the outputs of other watched files are skipped.
+func (w *watcher) scan() map[string]os.FileInfo {
+	found := map[string]os.FileInfo{}
+	for _, pattern := range w.paths {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			pattern = filepath.Join(pattern, w.match)
+		}
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if w.suffix != "" && strings.HasSuffix(match, w.suffix) {
+				continue
+			}
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				found[match] = info
+			}
+		}
+	}
+	return found
+}
+
+// outputPath returns the file the result of path is written to.
+func (w *watcher) outputPath(path string) string {
+	if w.opts.outputFile != "" {
+		return w.opts.outputFile
+	}
+	return pairedOutputPath(path, w.outDir, w.suffix)
+}
+
+// convert runs the mode on one file and rewrites its output, errors keep the previous output.
+// encoded art is written without a final newline like the resources, everything else ends with one.
+// the line endings and BOM of the input are kept unless --normalize.
+func (w *watcher) convert(path string) {
+	output := w.outputPath(path)
+	stamp := time.Now().Format("15:04:05")
+	if sameFile(path, output) {
+		fmt.Fprintf(os.Stderr, "%s %s: the output would overwrite the input\n", stamp, path)
+		return
+	}
+	content, format, err := functions.ReadTextFile(path)
+	if err == nil {
+		content, err = process(w.opts, content)
+	}
+	if err == nil {
+		if w.opts.normalize {
+			format = functions.NormalizedFormat
+		}
+		format.FinalNewline = !w.opts.encode
+		err = functions.WriteTxtFile(output, format.Apply(content))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", stamp, positionError(path, err))
+		return
+	}
+	fmt.Printf("%s %s -> %s\n", stamp, path, output)
+}
+
+// positionError formats the error as file:line:column: message when it has a position, like compilers do.
+func positionError(path string, err error) string {
+	var syntaxErr *functions.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return fmt.Sprintf("%s: %v", path, err)
+	}
+	// wrapped errors (like the frame of an animation) keep their prefix after the position.
+	msg := strings.Replace(err.Error(), syntaxErr.Error(), syntaxErr.Msg, 1)
+	return fmt.Sprintf("%s:%d:%d: %s", path, syntaxErr.Line, syntaxErr.Column, msg)
+}
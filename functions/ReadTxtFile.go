@@ -2,43 +2,136 @@ package functions
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
+//Stdio is the file name meaning standard input for reading and standard output for writing.
+const Stdio = "-"
+
+//MaxLineSize is the longest line in bytes the text readers accept, 0 allows lines of any length.
+var MaxLineSize = 0
+
+//LineTooLongError is returned for a line longer than MaxLineSize.
+type LineTooLongError struct {
+	Line	int //1-based
+	Limit	int
+}
+
+func (e *LineTooLongError) Error() string {
+	return fmt.Sprintf("line %d is longer than the maximum line size of %d bytes", e.Line, e.Limit)
+}
+
 /*This function reads from .txt file and returns string, when multiLine is false it will only
-read one line from the .txt file, otherwise it will return entire content as string*/
+read one line from the .txt file, otherwise it will return entire content as string.
+The file name "-" reads from standard input. "\r\n" line endings and a byte order mark are dropped,
+use ReadTextFile to keep the layout of the file.*/
 func ReadTxtFile(filePath string, multiLine bool) (string, error) {
+	if filePath == Stdio {
+		return readText(os.Stdin, multiLine)
+	}
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", fmt.Errorf("error opening file: %w", err)
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	return readText(file, multiLine)
+}
+
+//ReadRawFile reads the whole file byte for byte, "-" reads standard input.
+func ReadRawFile(filePath string) ([]byte, error) {
+	var data []byte
+	var err error
+	if filePath == Stdio {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return data, nil
+}
+
+//readText reads one line or all lines of the reader, lines can be of any length up to MaxLineSize.
+func readText(r io.Reader, multiLine bool) (string, error) {
+	reader := bufio.NewReader(r)
 
 	//check if multiLine is enabled and read the entire file.
 	if multiLine {
 		var builder strings.Builder
-		for scanner.Scan() {
-			builder.WriteString(scanner.Text())
+		for number := 1; ; number++ {
+			line, err := readLine(reader, number)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(line)
 			builder.WriteString("\n")
 		}
 
-		if err := scanner.Err(); err != nil {
-			return "", fmt.Errorf("error reading file: %w", err)
-		}
-
-		return builder.String(), nil
+		return strings.TrimPrefix(builder.String(), byteOrderMark), nil
 		//otherwise read only single line from .txt file.
 	} else {
-		if scanner.Scan() {
-			return scanner.Text(), nil
+		line, err := readLine(reader, 1)
+		if err == io.EOF {
+			return "", nil //file was empty
 		}
-		if err := scanner.Err(); err != nil {
-			return "", fmt.Errorf("error reading file: %w", err)
+		if err != nil {
+			return "", err
 		}
-		return "", nil //file was empty
+		return strings.TrimPrefix(line, byteOrderMark), nil
+	}
+}
+
+//readLine reads the next line without its line ending, io.EOF is returned once there are no more lines.
+func readLine(reader *bufio.Reader, number int) (string, error) {
+	line, ending, err := readTextLine(reader, number)
+	if err != nil {
+		return "", err
+	}
+	if ending == "" {
+		line = strings.TrimSuffix(line, "\r")
+	}
+	return line, nil
+}
+
+/*readTextLine reads the next line and returns it without its line ending, which is "\n", "\r\n"
+or empty for a last line without one. io.EOF is returned once there are no more lines.*/
+func readTextLine(reader *bufio.Reader, number int) (line string, ending string, err error) {
+	var data []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		data = append(data, chunk...)
+		//the limit is checked while reading, so a huge line is never held in memory.
+		if MaxLineSize > 0 && len(bytes.TrimRight(data, "\r\n")) > MaxLineSize {
+			return "", "", &LineTooLongError{Line: number, Limit: MaxLineSize}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(data) > 0 {
+			break //last line without a line ending
+		}
+		if err == io.EOF {
+			return "", "", io.EOF
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("error reading file: %w", err)
+		}
+		break
+	}
+	switch {
+	case bytes.HasSuffix(data, []byte("\r\n")):
+		ending = "\r\n"
+	case bytes.HasSuffix(data, []byte("\n")):
+		ending = "\n"
 	}
-}
\ No newline at end of file
+	return string(data[:len(data)-len(ending)]), ending, nil
+}
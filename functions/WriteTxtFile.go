@@ -1,21 +1,157 @@
 package functions
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
+	"strconv"
 )
-//writes the content from string to a .txt file
+
+//ErrFileExists is returned when the output file exists and overwriting it wasn't allowed.
+var ErrFileExists = errors.New("file already exists")
+
+//BackupSuffix is appended to the name of a replaced file kept as a backup.
+const BackupSuffix = ".bak"
+
+//new files are created with the mode os.Create uses, the umask applies to it. replaced files keep their mode.
+const newFileMode = 0o666
+
+//WriteOptions controls what WriteFile does with an existing file.
+type WriteOptions struct {
+	Overwrite	bool //replaces an existing file, otherwise ErrFileExists is returned
+	Backup		bool //keeps a copy of the replaced file as file.bak
+}
+
+//writes the content from string to a .txt file, "-" writes to standard output.
+//existing files are replaced atomically, see WriteFile.
 func WriteTxtFile(filePath string, content string) error {
-	file, err := os.Create(filePath)
+	return WriteFile(filePath, content, WriteOptions{Overwrite: true})
+}
+
+/*WriteFile writes the content atomically: it goes to a temporary file in the same directory,
+which is renamed over the target once everything is written, so a failed write never destroys the
+original file. The file mode of a replaced file is kept, new files respect the umask and symlinks are followed.
+Without opts.Overwrite an existing file is left alone and ErrFileExists is returned.
+"-" writes to standard output.*/
+func WriteFile(filePath string, content string, opts WriteOptions) error {
+	if filePath == Stdio {
+		if _, err := os.Stdout.WriteString(content); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+		return nil
+	}
+
+	target, mode, exists, err := writeTarget(filePath)
 	if err != nil {
-		return fmt.Errorf("error creating file: %w", err)
+		return err
+	}
+	if exists && !opts.Overwrite {
+		return fmt.Errorf("%s: %w", filePath, ErrFileExists)
+	}
+
+	temp, err := writeTemp(target, content, mode)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp) //only left over when something failed.
+
+	if exists && opts.Backup {
+		if err := backupFile(target, mode); err != nil {
+			return err
+		}
+	}
+	if !exists && !opts.Overwrite {
+		//linking fails when the file appeared in the meantime, so it is never clobbered.
+		if err := os.Link(temp, target); err == nil {
+			return nil
+		} else if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%s: %w", filePath, ErrFileExists)
+		}
+	}
+	if err := os.Rename(temp, target); err != nil {
+		return fmt.Errorf("error replacing file: %w", err)
 	}
-	defer file.Close()
+	return nil
+}
+
+//writeTarget resolves symlinks and returns the file to replace, its mode (0 when it doesn't exist) and whether it exists.
+func writeTarget(filePath string) (target string, mode fs.FileMode, exists bool, err error) {
+	target = filePath
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		target = resolved
+	}
+	info, err := os.Stat(target)
+	switch {
+	case err == nil && info.IsDir():
+		return "", 0, false, fmt.Errorf("error creating file: %s is a directory", filePath)
+	case err == nil:
+		return target, info.Mode().Perm(), true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return target, 0, false, nil
+	default:
+		return "", 0, false, fmt.Errorf("error creating file: %w", err)
+	}
+}
 
+/*writeTemp writes the content to a new temporary file next to target and returns its name.
+the file gets mode when it replaces an existing file, a mode of 0 leaves the mode of a new file to the umask.*/
+func writeTemp(target string, content string, mode fs.FileMode) (string, error) {
+	file, err := createTemp(target)
+	if err != nil {
+		//the error names the target instead of the temporary file.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			pathErr.Path = target
+		}
+		return "", fmt.Errorf("error creating file: %w", err)
+	}
+	name := file.Name()
 	_, err = file.WriteString(content)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && mode != 0 {
+		err = os.Chmod(name, mode)
+	}
 	if err != nil {
-		return fmt.Errorf("error writing to file: %w", err)
+		os.Remove(name)
+		return "", fmt.Errorf("error writing to file: %w", err)
+	}
+	return name, nil
+}
+
+//createTemp creates a new file named like ".target.tmp-123" next to target. unlike os.CreateTemp,
+//which always uses 0600, the file is created with newFileMode so the umask decides the mode of new files.
+func createTemp(target string) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".tmp-")
+	for range 10000 {
+		file, err := os.OpenFile(prefix+strconv.FormatUint(uint64(rand.Uint32()), 10), os.O_RDWR|os.O_CREATE|os.O_EXCL, newFileMode)
+		if !errors.Is(err, fs.ErrExist) {
+			return file, err
+		}
 	}
+	return nil, &fs.PathError{Op: "createtemp", Path: prefix + "*", Err: fs.ErrExist}
+}
 
+//backupFile keeps a copy of the file as file.bak, replacing an older backup.
+func backupFile(target string, mode fs.FileMode) error {
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return fmt.Errorf("error creating backup: %w", err)
+	}
+	temp, err := writeTemp(target+BackupSuffix, string(data), mode)
+	if err != nil {
+		return fmt.Errorf("error creating backup: %w", err)
+	}
+	if err := os.Rename(temp, target+BackupSuffix); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("error creating backup: %w", err)
+	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,131 @@
+package functions
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	tests := []struct {
+		name		string
+		existing	bool
+		opts		WriteOptions
+		wantErr		error
+		want		string // content of the file afterwards
+		backup		bool   // a backup with the old content is expected
+	}{
+		{"new file", false, WriteOptions{}, nil, "new", false},
+		{"no-clobber", true, WriteOptions{}, ErrFileExists, "old", false},
+		{"overwrite", true, WriteOptions{Overwrite: true}, nil, "new", false},
+		{"backup", true, WriteOptions{Overwrite: true, Backup: true}, nil, "new", true},
+		{"backup without a file", false, WriteOptions{Overwrite: true, Backup: true}, nil, "new", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "art.txt")
+			if test.existing {
+				if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := WriteFile(path, "new", test.opts)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("WriteFile error = %v, want %v", err, test.wantErr)
+			}
+			if data, _ := os.ReadFile(path); string(data) != test.want {
+				t.Errorf("file holds %q, want %q", data, test.want)
+			}
+			data, err := os.ReadFile(path + BackupSuffix)
+			if test.backup && string(data) != "old" {
+				t.Errorf("backup holds %q, %v, want %q", data, err, "old")
+			}
+			if !test.backup && !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("unexpected backup: %q, %v", data, err)
+			}
+			// the temporary file is gone whether the write succeeded or not.
+			files := 1
+			if test.backup {
+				files++
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != files {
+				t.Errorf("directory holds %d files, want %d", len(entries), files)
+			}
+		})
+	}
+}
+
+func TestWriteFileKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(path, []byte("old"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, "new", WriteOptions{Overwrite: true, Backup: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{path, path + BackupSuffix} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o750 {
+			t.Errorf("%s has mode %v, want %v", filepath.Base(name), info.Mode().Perm(), fs.FileMode(0o750))
+		}
+	}
+
+	newPath := filepath.Join(dir, "new.txt")
+	if err := WriteFile(newPath, "new", WriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// a new file gets the same mode as one made by os.Create, whatever the umask is.
+	created := filepath.Join(dir, "created.txt")
+	file, err := os.Create(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	info, err := os.Stat(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.Stat(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != want.Mode().Perm() {
+		t.Errorf("new file has mode %v, want %v", info.Mode().Perm(), want.Mode().Perm())
+	}
+}
+
+func TestWriteFileFollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+	if err := os.WriteFile(target, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+	if err := WriteFile(link, "new", WriteOptions{Overwrite: true}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("the symlink was replaced by a file")
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("target holds %q, want %q", data, "new")
+	}
+}
+
+func TestWriteFileRejectsDirectories(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteFile(dir, "new", WriteOptions{Overwrite: true}); err == nil {
+		t.Error("WriteFile to a directory succeeded, want an error")
+	}
+}
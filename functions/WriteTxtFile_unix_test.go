@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package functions
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+//new files must respect the umask, so decrypted plaintext isn't world readable under 'umask 077'.
+func TestWriteFileRespectsUmask(t *testing.T) {
+	defer syscall.Umask(syscall.Umask(0o077))
+
+	dir := t.TempDir()
+	for _, opts := range []WriteOptions{{}, {Overwrite: true}} {
+		path := filepath.Join(dir, fmt.Sprintf("plain-%t.txt", opts.Overwrite))
+		if err := WriteFile(path, "secret", opts); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("%s has mode %v under umask 077, want %v", filepath.Base(path), info.Mode().Perm(), fs.FileMode(0o600))
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"image/gif"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FrameMarker starts every frame of an animation, optionally followed by the frame delay in milliseconds.
+const FrameMarker = "@frame"
+
+// DefaultFrameDelay is used for frames without a delay.
+const DefaultFrameDelay = 100 * time.Millisecond
+
+// maxFrameDelay keeps a typo from freezing the player.
+const maxFrameDelay = time.Minute
+
+// Frame is one picture of an animation and how long it is shown.
+type Frame struct {
+	Art		string
+	Delay	time.Duration
+	line	int // line of the marker in the text the frame was split from, for error positions
+}
+
+/*
+	Animation is a sequence of frames in the multiline format, every frame starts with a marker line:
+
+		@frame 120
+		[3 -]
+		@frame
+		[3 =]
+
+	the frames are encoded or decoded like any other multiline art, the marker lines stay as they are.
+*/
+type Animation struct {
+	Frames	[]Frame
+}
+
+// isFrameMarker reports whether the line is a frame marker, with or without a delay.
+func isFrameMarker(line string) bool {
+	line = strings.TrimRight(line, " \r")
+	return line == FrameMarker || strings.HasPrefix(line, FrameMarker+" ")
+}
+
+/*
+	SplitFrames splits the text into frames at the marker lines, the frames are not decoded.
+	- blank lines before the first marker are ignored, any other text there is an error.
+	- a final newline is ignored, so files can end with one.
+	- errors report the line number of the invalid marker.
+*/
+func SplitFrames(text string) (Animation, error) {
+	var animation Animation
+	var lines []string
+	var frame *Frame
+
+	finish := func() {
+		if frame != nil {
+			frame.Art = strings.Join(lines, "\n")
+			animation.Frames = append(animation.Frames, *frame)
+		}
+	}
+
+	for i, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if !isFrameMarker(line) {
+			if frame == nil {
+				if strings.TrimSpace(line) != "" {
+					return Animation{}, fmt.Errorf("line %d: expected %q before the first frame", i+1, FrameMarker)
+				}
+				continue
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		delay, err := parseFrameDelay(line)
+		if err != nil {
+			return Animation{}, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		finish()
+		frame, lines = &Frame{Delay: delay, line: i + 1}, nil
+	}
+	finish()
+
+	if len(animation.Frames) == 0 {
+		return Animation{}, errors.New("animation has no frames")
+	}
+	return animation, nil
+}
+
+// parseFrameDelay reads the delay of a marker line, DefaultFrameDelay when there is none.
+func parseFrameDelay(line string) (time.Duration, error) {
+	value := strings.TrimSpace(strings.TrimPrefix(strings.TrimRight(line, " \r"), FrameMarker))
+	if value == "" {
+		return DefaultFrameDelay, nil
+	}
+	ms, err := strconv.Atoi(strings.TrimSuffix(value, "ms"))
+	delay := time.Duration(ms) * time.Millisecond
+	if err != nil || delay <= 0 || delay > maxFrameDelay {
+		return 0, fmt.Errorf("invalid frame delay %q, expected milliseconds up to %d", value, maxFrameDelay.Milliseconds())
+	}
+	return delay, nil
+}
+
+// String returns the animation in its text form, one marker line with the delay before every frame.
+func (a Animation) String() string {
+	var b strings.Builder
+	for i, frame := range a.Frames {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s %d\n%s", FrameMarker, frame.Delay.Milliseconds(), frame.Art)
+	}
+	return b.String()
+}
+
+// Duration returns how long one run through all frames takes.
+func (a Animation) Duration() time.Duration {
+	var total time.Duration
+	for _, frame := range a.Frames {
+		total += frame.Delay
+	}
+	return total
+}
+
+// DecodeAnimation splits the encoded animation into frames and decodes every frame.
+func DecodeAnimation(encoded string) (Animation, error) {
+	animation, err := SplitFrames(encoded)
+	if err != nil {
+		return Animation{}, err
+	}
+	for i, frame := range animation.Frames {
+		art, err := Decode(frame.Art, true)
+		if err != nil {
+			return Animation{}, frameError(i, frame, err)
+		}
+		animation.Frames[i].Art = art
+	}
+	return animation, nil
+}
+
+// EncodeAnimation splits the animation into frames and encodes every frame.
+func EncodeAnimation(art string) (Animation, error) {
+	animation, err := SplitFrames(art)
+	if err != nil {
+		return Animation{}, err
+	}
+	for i, frame := range animation.Frames {
+		encoded, err := Encode(frame.Art, true)
+		if err != nil {
+			return Animation{}, frameError(i, frame, err)
+		}
+		animation.Frames[i].Art = encoded
+	}
+	return animation, nil
+}
+
+// frameError moves the position of a syntax error from the frame to the whole animation.
+func frameError(i int, frame Frame, err error) error {
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) && frame.line > 0 {
+		moved := *syntaxErr
+		moved.Line += frame.line
+		return fmt.Errorf("frame %d: %w", i+1, &moved)
+	}
+	return fmt.Errorf("frame %d: %w", i+1, err)
+}
+
+// Size returns the width of the widest line and the height of the tallest frame.
+func (a Animation) Size() (width, height int) {
+	for _, frame := range a.Frames {
+		w, h := ArtDimensions(frame.Art)
+		width, height = max(width, w), max(height, h)
+	}
+	return width, height
+}
+
+/*
+	WriteGIF writes the decoded animation as an animated GIF that loops forever.
+	every frame is drawn on a canvas of the size of the largest frame, GIF delays are in 1/100 s.
+	the canvas has the limits of RenderImage and all frames together at most MaxAnimationPixels,
+	both are checked before any frame is drawn.
+*/
+func WriteGIF(w io.Writer, a Animation, opts RenderOptions) error {
+	width, height := a.Size()
+	imageWidth, imageHeight, err := imageSize(width, height, opts)
+	if err != nil {
+		return err
+	}
+	if float64(imageWidth)*float64(imageHeight)*float64(len(a.Frames)) > MaxAnimationPixels {
+		return fmt.Errorf("%d frames of %dx%d pixels: %w", len(a.Frames), imageWidth, imageHeight, ErrImageTooLarge)
+	}
+	canvas := strings.Repeat(" ", width)
+
+	var anim gif.GIF
+	for _, frame := range a.Frames {
+		// pad the frame so every image has the size of the canvas.
+		lines := strings.Split(strings.TrimSuffix(StripANSI(frame.Art), "\n"), "\n")
+		for len(lines) < height {
+			lines = append(lines, " ")
+		}
+		lines[0] += canvas[min(len([]rune(lines[0])), width):]
+
+		img, err := RenderImage(strings.Join(lines, "\n"), opts)
+		if err != nil {
+			return err
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, max(int(frame.Delay/(10*time.Millisecond)), 1))
+	}
+	if err := gif.EncodeAll(w, &anim); err != nil {
+		return fmt.Errorf("error encoding GIF: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package functions
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnimationRoundTrip(t *testing.T) {
+	art := "@frame 120\n---\n@frame\n===\n=-="
+	encoded, err := EncodeAnimation(art)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "@frame 120\n[3 -]\n@frame 100\n[3 =]\n[1 =][1 -][1 =]"
+	if encoded.String() != want {
+		t.Errorf("EncodeAnimation = %q, want %q", encoded.String(), want)
+	}
+	decoded, err := DecodeAnimation(encoded.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Frames) != 2 || decoded.Frames[0].Art != "---" || decoded.Frames[1].Art != "===\n=-=" {
+		t.Errorf("DecodeAnimation gave frames %+v", decoded.Frames)
+	}
+	if decoded.Duration() != 220*time.Millisecond {
+		t.Errorf("Duration() = %v, want 220ms", decoded.Duration())
+	}
+}
+
+func TestSplitFramesRejects(t *testing.T) {
+	tests := []struct {
+		name	string
+		text	string
+	}{
+		{"no frames", "just art"},
+		{"text before the first frame", "art\n@frame\n---"},
+		{"invalid delay", "@frame soon\n---"},
+		{"delay too long", "@frame 3600000\n---"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := SplitFrames(test.text); err == nil {
+				t.Errorf("SplitFrames(%q) succeeded, want an error", test.text)
+			}
+		})
+	}
+}
+
+func TestWriteGIFLimits(t *testing.T) {
+	frame := func(art string) Frame {
+		return Frame{Art: art, Delay: DefaultFrameDelay}
+	}
+	tests := []struct {
+		name	string
+		frames	[]Frame
+		wantErr	bool
+	}{
+		{"small", []Frame{frame("-"), frame("=")}, false},
+		{"wide and tall frames", []Frame{frame(strings.Repeat("#", 1000)), frame(strings.Repeat("\n", 999))}, true},
+		{"too many frames", slices.Repeat([]Frame{frame(strings.Repeat(strings.Repeat("#", 100)+"\n", 50))}, 100), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteGIF(&buf, Animation{Frames: test.frames}, DefaultRenderOptions())
+			if test.wantErr != errors.Is(err, ErrImageTooLarge) || !test.wantErr && err != nil {
+				t.Errorf("WriteGIF: error %v, want ErrImageTooLarge: %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package functions
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+	ANSI colour support.
+	art can contain SGR escape sequences ("\x1b[1;31m") for colours and bold text.
+	the encoder keeps every sequence as one token written as [sgr 1;31m],
+	so runs are never split across a sequence and the art round-trips exactly.
+*/
+
+// ansiSequence matches SGR escape sequences: ESC [ params m.
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// sgrParams matches the parameters of a [sgr ...] bracket.
+var sgrParams = regexp.MustCompile("^[0-9;]*m$")
+
+// sgrPrefix starts the bracket form of an escape sequence: [sgr <params>m]
+const sgrPrefix = "sgr"
+
+// ansiToken is either plain text or a single escape sequence.
+type ansiToken struct {
+	text	string
+	isSGR	bool
+}
+
+// HasANSI reports whether the text contains colour escape sequences.
+func HasANSI(s string) bool {
+	return ansiSequence.MatchString(s)
+}
+
+// StripANSI removes every colour escape sequence from the text.
+func StripANSI(s string) string {
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
+// splitANSI splits a line into text and escape sequence tokens, in order.
+func splitANSI(line string) []ansiToken {
+	var tokens []ansiToken
+	prev := 0
+	for _, match := range ansiSequence.FindAllStringIndex(line, -1) {
+		if match[0] > prev {
+			tokens = append(tokens, ansiToken{text: line[prev:match[0]]})
+		}
+		tokens = append(tokens, ansiToken{text: line[match[0]:match[1]], isSGR: true})
+		prev = match[1]
+	}
+	if prev < len(line) {
+		tokens = append(tokens, ansiToken{text: line[prev:]})
+	}
+	return tokens
+}
+
+// encodeSGR returns the bracket form of an escape sequence: "\x1b[1;31m" -> "[sgr 1;31m]".
+func encodeSGR(sequence string) string {
+	return "[" + sgrPrefix + " " + strings.TrimPrefix(sequence, "\x1b[") + "]"
+}
+
+// decodeSGR turns the parameters of a [sgr ...] bracket back into the escape sequence,
+// returns false when the parameters are not a valid SGR sequence.
+func decodeSGR(params string) (string, bool) {
+	if !sgrParams.MatchString(params) {
+		return "", false
+	}
+	return "\x1b[" + params, true
+}
+
+// standard colours for SGR 30-37 and 90-97 (bright), in the xterm palette.
+var ansiPalette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// sgrState is the text style set by the escape sequences seen so far.
+type sgrState struct {
+	foreground	string
+	background	string
+	bold		bool
+	italic		bool
+	underline	bool
+}
+
+// apply updates the state with the parameters of one escape sequence.
+func (state *sgrState) apply(params string) {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			code = 0 // empty parameters mean reset
+		}
+		switch {
+		case code == 0:
+			*state = sgrState{}
+		case code == 1:
+			state.bold = true
+		case code == 3:
+			state.italic = true
+		case code == 4:
+			state.underline = true
+		case code == 22:
+			state.bold = false
+		case code == 23:
+			state.italic = false
+		case code == 24:
+			state.underline = false
+		case code >= 30 && code <= 37:
+			state.foreground = ansiPalette[code-30]
+		case code >= 90 && code <= 97:
+			state.foreground = ansiPalette[code-90+8]
+		case code == 39:
+			state.foreground = ""
+		case code >= 40 && code <= 47:
+			state.background = ansiPalette[code-40]
+		case code >= 100 && code <= 107:
+			state.background = ansiPalette[code-100+8]
+		case code == 49:
+			state.background = ""
+		case code == 38 || code == 48:
+			colour, used := extendedColour(codes[i+1:])
+			i += used
+			if code == 38 {
+				state.foreground = colour
+			} else {
+				state.background = colour
+			}
+		}
+	}
+}
+
+// extendedColour parses "5;n" (256 colours) or "2;r;g;b" (true colour), returning the colour and parameters used.
+func extendedColour(params []string) (string, int) {
+	number := func(i int) int {
+		if i >= len(params) {
+			return 0
+		}
+		n, _ := strconv.Atoi(params[i])
+		return min(max(n, 0), 255)
+	}
+	if len(params) == 0 {
+		return "", 0
+	}
+	switch params[0] {
+	case "5":
+		n := number(1)
+		switch {
+		case n < 16:
+			return ansiPalette[n], 2
+		case n < 232:
+			// 6x6x6 colour cube
+			n -= 16
+			level := func(v int) int {
+				if v == 0 {
+					return 0
+				}
+				return 55 + v*40
+			}
+			return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6)), 2
+		default:
+			grey := 8 + (n-232)*10
+			return fmt.Sprintf("#%02x%02x%02x", grey, grey, grey), 2
+		}
+	case "2":
+		return fmt.Sprintf("#%02x%02x%02x", number(1), number(2), number(3)), 4
+	}
+	return "", 1
+}
+
+// style returns the CSS for the state, empty when the text is unstyled.
+func (state sgrState) style() string {
+	var css []string
+	if state.foreground != "" {
+		css = append(css, "color:"+state.foreground)
+	}
+	if state.background != "" {
+		css = append(css, "background-color:"+state.background)
+	}
+	if state.bold {
+		css = append(css, "font-weight:bold")
+	}
+	if state.italic {
+		css = append(css, "font-style:italic")
+	}
+	if state.underline {
+		css = append(css, "text-decoration:underline")
+	}
+	return strings.Join(css, ";")
+}
+
+// ANSIToHTML escapes the text for HTML and turns colour escape sequences into styled <span> elements.
+func ANSIToHTML(s string) string {
+	var b strings.Builder
+	var state sgrState
+	open := false
+	for _, token := range splitANSI(s) {
+		if !token.isSGR {
+			b.WriteString(html.EscapeString(token.text))
+			continue
+		}
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+		state.apply(strings.TrimSuffix(strings.TrimPrefix(token.text, "\x1b["), "m"))
+		if style := state.style(); style != "" {
+			b.WriteString(`<span style="` + style + `">`)
+			open = true
+		}
+	}
+	if open {
+		b.WriteString("</span>")
+	}
+	return b.String()
+}
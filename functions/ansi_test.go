@@ -0,0 +1,101 @@
+package functions
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitANSI(t *testing.T) {
+	tests := []struct {
+		line	string
+		want	[]ansiToken
+	}{
+		{"", nil},
+		{"##", []ansiToken{{text: "##"}}},
+		{"\x1b[31m##", []ansiToken{{"\x1b[31m", true}, {text: "##"}}},
+		{"a\x1b[1;31mb\x1b[0m", []ansiToken{{text: "a"}, {"\x1b[1;31m", true}, {text: "b"}, {"\x1b[0m", true}}},
+		{"\x1b[m\x1b[4m", []ansiToken{{"\x1b[m", true}, {"\x1b[4m", true}}},
+		// only SGR sequences are tokens, other escape sequences stay text.
+		{"\x1b[2J#", []ansiToken{{text: "\x1b[2J#"}}},
+	}
+	for _, test := range tests {
+		if got := splitANSI(test.line); !slices.Equal(got, test.want) {
+			t.Errorf("splitANSI(%q) = %+v, want %+v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestSGRRoundTrip(t *testing.T) {
+	for _, sequence := range []string{"\x1b[m", "\x1b[0m", "\x1b[1;31m", "\x1b[38;5;208m", "\x1b[48;2;10;20;30m"} {
+		encoded := encodeSGR(sequence)
+		params := encoded[len("[sgr ") : len(encoded)-1]
+		decoded, ok := decodeSGR(params)
+		if !ok || decoded != sequence {
+			t.Errorf("%q encodes to %q and decodes to %q, %v", sequence, encoded, decoded, ok)
+		}
+	}
+	if got := encodeSGR("\x1b[1;31m"); got != "[sgr 1;31m]" {
+		t.Errorf("encodeSGR = %q, want %q", got, "[sgr 1;31m]")
+	}
+	for _, params := range []string{"31", "31mm", "3a1m", "31m]", " 31m"} {
+		if _, ok := decodeSGR(params); ok {
+			t.Errorf("decodeSGR(%q) succeeded, want it rejected", params)
+		}
+	}
+
+	// the whole art round trips through the encoder and decoder with its sequences intact.
+	art := "\x1b[1;31m###\x1b[0m  \x1b[38;5;21m//\x1b[m\n\x1b[42m  \x1b[0m"
+	encoded, err := Encode(art, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(encoded, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != art {
+		t.Errorf("round trip through %q = %q, want %q", encoded, decoded, art)
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"plain", "plain"},
+		{"\x1b[1;31m##\x1b[0m", "##"},
+		{"a\x1b[mb\x1b[38;2;1;2;3mc", "abc"},
+		{"\x1b[31m/\\\n\x1b[0m\\/", "/\\\n\\/"},
+		{"\x1b[2J#", "\x1b[2J#"},
+	}
+	for _, test := range tests {
+		if got := StripANSI(test.input); got != test.want {
+			t.Errorf("StripANSI(%q) = %q, want %q", test.input, got, test.want)
+		}
+		if HasANSI(test.want) {
+			t.Errorf("HasANSI(%q) = true after stripping", test.want)
+		}
+	}
+}
+
+func TestANSIToHTML(t *testing.T) {
+	tests := []struct {
+		name	string
+		input	string
+		want	string
+	}{
+		{"plain text is escaped", `<b>&"'`, "&lt;b&gt;&amp;&#34;&#39;"},
+		{"coloured text is escaped", "\x1b[31m<script>\x1b[0m", `<span style="color:#cd0000">&lt;script&gt;</span>`},
+		{"styles add up", "\x1b[1m\x1b[4;44ma", `<span style="font-weight:bold"></span><span style="background-color:#0000ee;font-weight:bold;text-decoration:underline">a</span>`},
+		{"reset closes the span", "\x1b[92ma\x1b[mb", `<span style="color:#00ff00">a</span>b`},
+		{"unclosed span", "\x1b[3ma", `<span style="font-style:italic">a</span>`},
+		{"256 colours", "\x1b[38;5;196ma\x1b[38;5;244mb", `<span style="color:#ff0000">a</span><span style="color:#808080">b</span>`},
+		{"true colour", "\x1b[48;2;1;2;300ma", `<span style="background-color:#0102ff">a</span>`},
+		{"other sequences are escaped text", "\x1b[2J&", "\x1b[2J&amp;"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ANSIToHTML(test.input); got != test.want {
+				t.Errorf("ANSIToHTML(%q) =\n%s\nwant\n%s", test.input, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package functions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Art bundles are files carrying art together with the list of transforms applied to it,
+so the receiver can undo them without knowing how the file was made.
+
+ARTBUNDLE 1
+transforms: encode,xor,base64
+multiline: true
+checksum: sha256:<hex of the payload>
+content-checksum: sha256:<hex of the original content>
+length: <payload size in bytes>
+
+<payload>
+
+the header is text, the payload may be binary (e.g. when the last transform is xor).
+the payload checksum catches corrupted files, the content checksum catches a wrong key.
+*/
+const (
+	BundleMagic   = "ARTBUNDLE"
+	BundleVersion = 1
+)
+
+// Bundle is a parsed art bundle.
+type Bundle struct {
+	Version         int
+	Transforms      []string // pipeline steps applied to the original content, in order
+	MultiLine       bool     // art steps were run line by line
+	ContentChecksum string   // checksum of the original content, checked after unpacking
+	Payload         []byte
+}
+
+// PackBundle runs the pipeline on the content and wraps the result in a bundle.
+func PackBundle(content string, pipeline Pipeline, opts PipelineOptions) (Bundle, error) {
+	payload, err := pipeline.Run(content, opts)
+	if err != nil {
+		return Bundle{}, err
+	}
+	return Bundle{
+		Version:         BundleVersion,
+		Transforms:      append([]string(nil), pipeline.Steps...),
+		MultiLine:       opts.MultiLine,
+		ContentChecksum: payloadChecksum([]byte(content)),
+		Payload:         []byte(payload),
+	}, nil
+}
+
+// IsBundle reports whether data starts with the bundle magic.
+func IsBundle(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(BundleMagic+" "))
+}
+
+// Marshal returns the bundle in its file format.
+func (b Bundle) Marshal() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %d\n", BundleMagic, b.Version)
+	fmt.Fprintf(&buf, "transforms: %s\n", strings.Join(b.Transforms, ","))
+	fmt.Fprintf(&buf, "multiline: %t\n", b.MultiLine)
+	fmt.Fprintf(&buf, "checksum: %s\n", payloadChecksum(b.Payload))
+	fmt.Fprintf(&buf, "content-checksum: %s\n", b.ContentChecksum)
+	fmt.Fprintf(&buf, "length: %d\n\n", len(b.Payload))
+	buf.Write(b.Payload)
+	return buf.Bytes()
+}
+
+/*
+ParseBundle reads a bundle from its file format.
+- checks the magic and the version.
+- every transform must be a known pipeline step.
+- the payload length and checksum must match the header.
+*/
+func ParseBundle(data []byte) (Bundle, error) {
+	if !IsBundle(data) {
+		return Bundle{}, errors.New("not an art bundle: missing ARTBUNDLE header")
+	}
+
+	headerEnd := bytes.Index(data, []byte("\n\n"))
+	if headerEnd == -1 {
+		return Bundle{}, errors.New("malformed bundle: header is not terminated by an empty line")
+	}
+	lines := strings.Split(string(data[:headerEnd]), "\n")
+	payload := data[headerEnd+2:]
+
+	var bundle Bundle
+	version, err := strconv.Atoi(strings.TrimPrefix(lines[0], BundleMagic+" "))
+	if err != nil {
+		return Bundle{}, fmt.Errorf("malformed bundle: invalid version %q", lines[0])
+	}
+	if version != BundleVersion {
+		return Bundle{}, fmt.Errorf("unsupported bundle version %d, this tool reads version %d", version, BundleVersion)
+	}
+	bundle.Version = version
+
+	fields := make(map[string]string)
+	for i, line := range lines[1:] {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return Bundle{}, fmt.Errorf("malformed bundle: header line %d has no ':'", i+2)
+		}
+		fields[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	for _, name := range []string{"transforms", "multiline", "checksum", "content-checksum", "length"} {
+		if _, ok := fields[name]; !ok {
+			return Bundle{}, fmt.Errorf("malformed bundle: header is missing %q", name)
+		}
+	}
+
+	if fields["transforms"] != "" {
+		pipeline, err := ParsePipeline(fields["transforms"])
+		if err != nil {
+			return Bundle{}, fmt.Errorf("malformed bundle: %w", err)
+		}
+		bundle.Transforms = pipeline.Steps
+	}
+	if bundle.MultiLine, err = strconv.ParseBool(fields["multiline"]); err != nil {
+		return Bundle{}, fmt.Errorf("malformed bundle: invalid multiline value %q", fields["multiline"])
+	}
+
+	length, err := strconv.Atoi(fields["length"])
+	if err != nil || length < 0 {
+		return Bundle{}, fmt.Errorf("malformed bundle: invalid length %q", fields["length"])
+	}
+	if length != len(payload) {
+		return Bundle{}, fmt.Errorf("bundle is corrupted: payload is %d bytes, header says %d", len(payload), length)
+	}
+	if checksum := payloadChecksum(payload); checksum != fields["checksum"] {
+		return Bundle{}, fmt.Errorf("bundle is corrupted: checksum mismatch, got %s, header says %s", checksum, fields["checksum"])
+	}
+	bundle.ContentChecksum = fields["content-checksum"]
+	bundle.Payload = payload
+	return bundle, nil
+}
+
+// Pipeline returns the pipeline that was used to make the bundle.
+func (b Bundle) Pipeline() Pipeline {
+	return Pipeline{Steps: append([]string(nil), b.Transforms...)}
+}
+
+// NeedsKey reports whether unpacking the bundle requires a key.
+func (b Bundle) NeedsKey() bool {
+	for _, step := range b.Transforms {
+		if step == "xor" {
+			return true
+		}
+	}
+	return false
+}
+
+// Unpack reverses every transform of the bundle and returns the original content,
+// the content checksum must match so a wrong key doesn't silently produce garbage.
+func (b Bundle) Unpack(opts PipelineOptions) (string, error) {
+	content := string(b.Payload)
+	if len(b.Transforms) > 0 {
+		inverse, err := b.Pipeline().Inverse()
+		if err != nil {
+			return "", err
+		}
+		opts.MultiLine = b.MultiLine
+		if content, err = inverse.Run(content, opts); err != nil {
+			if b.NeedsKey() {
+				return "", fmt.Errorf("%w (is the key correct?)", err)
+			}
+			return "", err
+		}
+	}
+	if payloadChecksum([]byte(content)) != b.ContentChecksum {
+		if b.NeedsKey() {
+			return "", errors.New("unpacked content doesn't match the content checksum, the key is probably wrong")
+		}
+		return "", errors.New("unpacked content doesn't match the content checksum")
+	}
+	return content, nil
+}
+
+// payloadChecksum returns the checksum of the payload as written in the header.
+func payloadChecksum(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
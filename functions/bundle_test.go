@@ -0,0 +1,93 @@
+package functions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const bundleArt = "  /\\\n /##\\\n/####\\"
+
+func TestBundleRoundTrip(t *testing.T) {
+	tests := []struct {
+		pipeline	string
+		key			string
+	}{
+		{"encode", ""},
+		{"seal", "secret"},
+		{"encode,rot13,base32", ""},
+		{"mirror,encode,xor", "k"},
+	}
+	for _, test := range tests {
+		t.Run(test.pipeline, func(t *testing.T) {
+			pipeline, err := ParsePipeline(test.pipeline)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := PipelineOptions{Key: []byte(test.key), MultiLine: true}
+			bundle, err := PackBundle(bundleArt, pipeline, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseBundle(bundle.Marshal())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.NeedsKey() != (test.key != "") {
+				t.Errorf("NeedsKey = %v", parsed.NeedsKey())
+			}
+			content, err := parsed.Unpack(PipelineOptions{Key: []byte(test.key)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content != bundleArt {
+				t.Errorf("Unpack = %q, want %q", content, bundleArt)
+			}
+		})
+	}
+}
+
+func TestUnpackWrongKey(t *testing.T) {
+	pipeline, _ := ParsePipeline("seal")
+	bundle, err := PackBundle(bundleArt, pipeline, PipelineOptions{Key: []byte("secret"), MultiLine: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.Unpack(PipelineOptions{Key: []byte("wrong")}); err == nil || !strings.Contains(err.Error(), "key") {
+		t.Errorf("Unpack with the wrong key: %v, want an error about the key", err)
+	}
+}
+
+func TestParseBundleRejects(t *testing.T) {
+	pipeline, _ := ParsePipeline("encode")
+	bundle, err := PackBundle(bundleArt, pipeline, PipelineOptions{MultiLine: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := bundle.Marshal()
+	replace := func(old, new string) []byte {
+		return bytes.Replace(valid, []byte(old), []byte(new), 1)
+	}
+
+	tests := []struct {
+		name	string
+		data	[]byte
+		want	string
+	}{
+		{"not a bundle", []byte("[5 #]"), "missing ARTBUNDLE header"},
+		{"unterminated header", bytes.SplitN(valid, []byte("\n\n"), 2)[0], "not terminated"},
+		{"version", replace("ARTBUNDLE 1", "ARTBUNDLE 2"), "unsupported bundle version"},
+		{"missing field", replace("multiline: true\n", ""), `missing "multiline"`},
+		{"unknown transform", replace("transforms: encode", "transforms: explode"), "unknown pipeline step"},
+		{"length", replace("length: ", "length: 1"), "payload is"},
+		{"corrupted payload", append(bytes.Clone(valid[:len(valid)-1]), '!'), "checksum mismatch"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseBundle(test.data)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ParseBundle error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// alignments for concatenation, horizontal ones for vconcat and vertical ones for hconcat.
+const (
+	AlignStart	= "start"	// top or left
+	AlignCenter	= "center"
+	AlignEnd	= "end"		// bottom or right
+)
+
+// compose limits, so a typo can't produce huge art.
+const (
+	maxTiles			= 100		// tiles of Tile
+	maxOverlayOffset	= 1000		// columns and rows Overlay can move the top piece
+	maxOverlayCells		= 1 << 22	// characters of the overlay result
+)
+
+/*
+	the compositing operations work on decoded art and return art without a final newline and
+	without trailing spaces, so the result can be passed straight to EncodeString.
+	coloured art and animations are rejected like in the transforms.
+*/
+
+// composeLines checks that the piece can be composed and splits it into a grid.
+func composeLines(art string) ([][]rune, error) {
+	if HasANSI(art) {
+		return nil, errors.New("coloured art can't be composed, strip the colours first")
+	}
+	return artLines(art), nil
+}
+
+// composeAll splits every piece into a grid.
+func composeAll(pieces []string) ([][][]rune, error) {
+	if len(pieces) == 0 {
+		return nil, errors.New("nothing to compose")
+	}
+	grids := make([][][]rune, len(pieces))
+	for i, piece := range pieces {
+		lines, err := composeLines(piece)
+		if err != nil {
+			return nil, fmt.Errorf("piece %d: %w", i+1, err)
+		}
+		grids[i] = lines
+	}
+	return grids, nil
+}
+
+// alignOffset returns where content of size fits into space with the alignment.
+func alignOffset(space, size int, align string) int {
+	switch align {
+	case AlignCenter:
+		return (space - size) / 2
+	case AlignEnd:
+		return space - size
+	default:
+		return 0
+	}
+}
+
+// checkCompose validates the options shared by the concatenations.
+func checkCompose(spacing int, align string) error {
+	if spacing < 0 {
+		return errors.New("spacing can't be negative")
+	}
+	if align != AlignStart && align != AlignCenter && align != AlignEnd {
+		return fmt.Errorf("invalid alignment %q, expected start, center or end", align)
+	}
+	return nil
+}
+
+// HConcat puts the pieces side by side, spacing columns apart, aligned vertically (start is the top).
+func HConcat(pieces []string, spacing int, align string) (string, error) {
+	if err := checkCompose(spacing, align); err != nil {
+		return "", err
+	}
+	grids, err := composeAll(pieces)
+	if err != nil {
+		return "", err
+	}
+
+	height := 0
+	for _, grid := range grids {
+		height = max(height, len(grid))
+	}
+	result := make([][]rune, height)
+	for i, grid := range grids {
+		width, rows := artSize(grid)
+		top := alignOffset(height, rows, align)
+		for y := range height {
+			if i > 0 {
+				result[y] = append(result[y], []rune(strings.Repeat(" ", spacing))...)
+			}
+			for x := range width {
+				result[y] = append(result[y], cellAt(grid, x, y-top))
+			}
+		}
+	}
+	return joinLines(result, true, false), nil
+}
+
+// VConcat stacks the pieces, spacing empty lines apart, aligned horizontally (start is the left).
+func VConcat(pieces []string, spacing int, align string) (string, error) {
+	if err := checkCompose(spacing, align); err != nil {
+		return "", err
+	}
+	grids, err := composeAll(pieces)
+	if err != nil {
+		return "", err
+	}
+
+	width := 0
+	for _, grid := range grids {
+		w, _ := artSize(grid)
+		width = max(width, w)
+	}
+	var result [][]rune
+	for i, grid := range grids {
+		if i > 0 {
+			for range spacing {
+				result = append(result, nil)
+			}
+		}
+		w, _ := artSize(grid)
+		indent := []rune(strings.Repeat(" ", alignOffset(width, w, align)))
+		for _, line := range grid {
+			result = append(result, append(append([]rune{}, indent...), line...))
+		}
+	}
+	return joinLines(result, true, false), nil
+}
+
+/*
+	Overlay draws top over base with its top left corner at column x, row y.
+	- spaces of top are transparent unless opaque is set.
+	- the result grows when top reaches past base.
+*/
+func Overlay(base, top string, x, y int, opaque bool) (string, error) {
+	if x < 0 || y < 0 || x > maxOverlayOffset || y > maxOverlayOffset {
+		return "", fmt.Errorf("invalid overlay offset %d,%d, expected 0 to %d", x, y, maxOverlayOffset)
+	}
+	baseLines, err := composeLines(base)
+	if err != nil {
+		return "", err
+	}
+	topLines, err := composeLines(top)
+	if err != nil {
+		return "", err
+	}
+
+	baseWidth, baseHeight := artSize(baseLines)
+	topWidth, topHeight := artSize(topLines)
+	width, height := max(baseWidth, x+topWidth), max(baseHeight, y+topHeight)
+	if height > 0 && width > maxOverlayCells/height {
+		return "", fmt.Errorf("the overlay would be %d columns by %d rows, more than %d characters", width, height, maxOverlayCells)
+	}
+
+	result := make([][]rune, height)
+	for row := range height {
+		result[row] = make([]rune, width)
+		for column := range width {
+			result[row][column] = cellAt(baseLines, column, row)
+		}
+	}
+	for row, line := range topLines {
+		for column, r := range line {
+			if r != ' ' || opaque {
+				result[y+row][x+column] = r
+			}
+		}
+		// opaque overlays also cover the base where their shorter lines end.
+		if opaque {
+			for column := len(line); column < topWidth; column++ {
+				result[y+row][x+column] = ' '
+			}
+		}
+	}
+	return joinLines(result, true, false), nil
+}
+
+// Tile repeats the piece columns times side by side and rows times below each other.
+func Tile(piece string, columns, rows int) (string, error) {
+	// columns*rows could overflow, so the product is checked by division.
+	if columns < 1 || rows < 1 || columns > maxTiles || rows > maxTiles/columns {
+		return "", fmt.Errorf("invalid tiling %dx%d, expected at least 1x1 and at most %d tiles", columns, rows, maxTiles)
+	}
+	lines, err := composeLines(piece)
+	if err != nil {
+		return "", err
+	}
+
+	width, _ := artSize(lines)
+	result := make([][]rune, 0, len(lines)*rows)
+	for range rows {
+		for y := range lines {
+			row := make([]rune, 0, width*columns)
+			for range columns {
+				for x := range width {
+					row = append(row, cellAt(lines, x, y))
+				}
+			}
+			result = append(result, row)
+		}
+	}
+	return joinLines(result, true, false), nil
+}
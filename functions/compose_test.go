@@ -0,0 +1,125 @@
+package functions
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHConcat(t *testing.T) {
+	tests := []struct {
+		name	string
+		pieces	[]string
+		spacing	int
+		align	string
+		want	string
+	}{
+		{"top", []string{"ab\ncd", "x"}, 1, AlignStart, "ab x\ncd"},
+		{"bottom", []string{"ab\ncd", "x"}, 0, AlignEnd, "ab\ncdx"},
+		{"center", []string{"a\nb\nc", "x"}, 0, AlignCenter, "a\nbx\nc"},
+		{"ragged piece", []string{"a\nbbb", "x\ny"}, 0, AlignStart, "a  x\nbbby"},
+		{"single piece", []string{"ab  \ncd"}, 0, AlignStart, "ab\ncd"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := HConcat(test.pieces, test.spacing, test.align)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("HConcat = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVConcat(t *testing.T) {
+	tests := []struct {
+		name	string
+		pieces	[]string
+		spacing	int
+		align	string
+		want	string
+	}{
+		{"left", []string{"abc", "x"}, 0, AlignStart, "abc\nx"},
+		{"right with spacing", []string{"abc", "x"}, 1, AlignEnd, "abc\n\n  x"},
+		{"center", []string{"abcde", "x"}, 0, AlignCenter, "abcde\n  x"},
+		{"ragged piece", []string{"a\nbbb", "xx"}, 0, AlignEnd, "a\nbbb\n xx"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := VConcat(test.pieces, test.spacing, test.align)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("VConcat = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	base := "#####\n#####\n#####"
+	tests := []struct {
+		name	string
+		top		string
+		x, y	int
+		opaque	bool
+		want	string
+	}{
+		{"transparent spaces", "a b", 1, 1, false, "#####\n#a#b#\n#####"},
+		{"opaque spaces", "a b", 1, 1, true, "#####\n#a b#\n#####"},
+		{"opaque ragged lines", "ab\nc", 0, 0, true, "ab###\nc ###\n#####"},
+		{"grows past the base", "xy", 4, 3, false, "#####\n#####\n#####\n    xy"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Overlay(base, test.top, test.x, test.y, test.opaque)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("Overlay = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTile(t *testing.T) {
+	got, err := Tile("ab\nc", 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ababab\nc c c\nababab\nc c c"; got != want {
+		t.Errorf("Tile = %q, want %q", got, want)
+	}
+}
+
+func TestComposeLimits(t *testing.T) {
+	wide := strings.Repeat("#", 5000)
+	tests := []struct {
+		name	string
+		compose	func() (string, error)
+		want	string
+	}{
+		{"no tiles", func() (string, error) { return Tile("#", 0, 1) }, "invalid tiling"},
+		{"too many tiles", func() (string, error) { return Tile("#", 11, 10) }, "invalid tiling"},
+		{"overflowing tiles", func() (string, error) { return Tile("#", math.MaxInt/2+1, 4) }, "invalid tiling"},
+		{"negative offset", func() (string, error) { return Overlay("#", "#", -1, 0, false) }, "invalid overlay offset"},
+		{"huge offset", func() (string, error) { return Overlay("#", "#", 3000, 2000, false) }, "invalid overlay offset"},
+		{"huge result", func() (string, error) { return Overlay(wide, wide, 0, 1000, false) }, "more than"},
+		{"coloured art", func() (string, error) { return Tile("\x1b[31m#", 2, 1) }, "coloured art"},
+		{"nothing to compose", func() (string, error) { return HConcat(nil, 0, AlignStart) }, "nothing to compose"},
+		{"negative spacing", func() (string, error) { return VConcat([]string{"#"}, -1, AlignStart) }, "spacing"},
+		{"unknown alignment", func() (string, error) { return HConcat([]string{"#"}, 0, "middle") }, "invalid alignment"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.compose()
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,16 @@ package functions
 
 import (
 	"encoding/base64"
+	"fmt"
 )
+
+// directions for the XOR cypher, auto guesses from the input like Xorify does.
+const (
+	DirectionAuto    = "auto"
+	DirectionEncrypt = "encrypt"
+	DirectionDecrypt = "decrypt"
+)
+
 //just a simple Xor encrypt/decrypt function
 func Xorify(input string, key string) (string, error) {
 	if key == "" {
@@ -19,10 +28,7 @@ func Xorify(input string, key string) (string, error) {
 		data = []byte(input)
 	}
 
-	output := make([]byte, len(data))
-	for i := 0; i < len(data); i++ {
-		output[i] = data[i] ^ keyBytes[i%len(keyBytes)]
-	}
+	output := XorBytes(data, keyBytes)
 
 	if decrypting {
 		//decrypted plaintext
@@ -32,20 +38,70 @@ func Xorify(input string, key string) (string, error) {
 		return base64.StdEncoding.EncodeToString(output), nil
 	}
 }
+
+// XorBytes xors every byte of data with the repeating key.
+func XorBytes(data []byte, key []byte) []byte {
+	output := make([]byte, len(data))
+	for i := 0; i < len(data); i++ {
+		output[i] = data[i] ^ key[i%len(key)]
+	}
+	return output
+}
+
+/*
+XorifyWith is Xorify with an explicit cyphertext encoding and direction.
+  - encrypt: xors the plaintext input and returns it in the selected encoding.
+  - decrypt: input must be valid in the selected encoding, otherwise an error is returned.
+  - auto: decrypts when the input is valid in the encoding, encrypts otherwise.
+*/
+func XorifyWith(input string, key []byte, encoding, direction string) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	switch direction {
+	case DirectionEncrypt:
+		return EncodeBytes(XorBytes([]byte(input), key), encoding)
+	case DirectionDecrypt:
+		data, err := DecodeBytes(input, encoding)
+		if err != nil {
+			return "", err
+		}
+		return string(XorBytes(data, key)), nil
+	case DirectionAuto, "":
+		data, err := DecodeBytes(input, encoding)
+		if err != nil {
+			return EncodeBytes(XorBytes([]byte(input), key), encoding)
+		}
+		return string(XorBytes(data, key)), nil
+	default:
+		return "", fmt.Errorf("unsupported direction %q, expected encrypt, decrypt or auto", direction)
+	}
+}
+
+// ResolveDirection turns the auto direction into encrypt or decrypt, the same way XorifyWith decides.
+func ResolveDirection(input, encoding, direction string) string {
+	if direction != DirectionAuto && direction != "" {
+		return direction
+	}
+	if _, err := DecodeBytes(input, encoding); err != nil {
+		return DirectionEncrypt
+	}
+	return DirectionDecrypt
+}
+
 //ROT13 encrypt/decrypt function.
+//only ASCII letters change, so it works byte by byte and keeps multi-byte characters and binary input intact.
 func Rot13ify(input string) string {
-	output := make([]rune, len(input))
-	for i, r := range input {
+	output := []byte(input)
+	for i, b := range output {
 		switch {
-			case r >= 'A' && r <= 'Z':
-				output[i] = 'A' + (r-'A'+13)%26
-			case r >= 'a' && r <= 'z':
-				output[i] = 'a' + (r-'a'+13)%26
-			default:
-				output[i] = r
+			case b >= 'A' && b <= 'Z':
+				output[i] = 'A' + (b-'A'+13)%26
+			case b >= 'a' && b <= 'z':
+				output[i] = 'a' + (b-'a'+13)%26
 		}
 	}
 
 	return string(output)
 }
-
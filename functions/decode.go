@@ -1,28 +1,93 @@
 package functions
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
+
+//SyntaxError describes malformed input and where it was found.
+type SyntaxError struct {
+	Line	int //1-based
+	Column	int //1-based, counted in characters
+	Msg		string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+//newSyntaxError returns the error for the byte offset of text, converted to a line and column.
+func newSyntaxError(text string, offset int, msg string) *SyntaxError {
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return &SyntaxError{Line: line, Column: column, Msg: msg}
+}
+
+//DecodeString keeps the original behaviour of returning "Error\n" for malformed input.
 func DecodeString(input string, multiline bool) string {
+	result, err := Decode(input, multiline)
+	if err != nil {
+		return printError()
+	}
+	return result
+}
+
+//Decode expands the input, malformed input is reported as a *SyntaxError with its position.
+func Decode(input string, multiline bool) (string, error) {
 	if multiline {
 		lines := strings.Split(input, "\n")
 		var resultLines []string
-		for _, line := range lines {
-			result := decodeLine(line)
-			if result == "Error\n" {
-				return result
+		for i, line := range lines {
+			result, err := decodeLine(line)
+			if err != nil {
+				err.Line = i + 1
+				return "", err
 			}
 			resultLines = append(resultLines, result)
 		}
-		return strings.Join(resultLines, "\n")
+		return strings.Join(resultLines, "\n"), nil
 	}
 
-	return decodeLine(input)
+	result, err := decodeLine(input)
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+//encodedToken is a piece of an encoded line: plain text, a run or a colour escape sequence.
+type encodedToken struct {
+	offset	int //byte offset of the token in the line
+	end		int //byte offset after the token
+	text	string //plain text, or the escape sequence of a colour token
+	count	int
+	pattern	string
+	isRun	bool
+	isSGR	bool
 }
 
-func decodeLine(input string) string {
+//decodes one line, errors hold the line and column within the input.
+func decodeLine(input string) (string, *SyntaxError) {
 	var result strings.Builder
+	err := scanEncodedLine(input, func(token encodedToken) {
+		if token.isRun {
+			//appends repeated pattern to the result.
+			result.WriteString(strings.Repeat(token.pattern, token.count))
+		} else {
+			result.WriteString(token.text)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+//scanEncodedLine validates the line and hands its tokens to visit in order, without expanding the runs.
+func scanEncodedLine(input string, visit func(encodedToken)) *SyntaxError {
 	length := len(input)
 
 	for i := 0; i < length; {
@@ -31,7 +96,7 @@ func decodeLine(input string) string {
 			//finds the index of the closing "]" from current position forward.
 			end := strings.IndexByte(input[i:], ']')
 			if end == -1 { //no closing found == malformed input.
-				return printError()
+				return newSyntaxError(input, i, "missing closing bracket")
 			}
 			//adjusting end
 			end += i
@@ -39,7 +104,7 @@ func decodeLine(input string) string {
 
 			//making sure there is atleast one space.
 			if !strings.Contains(content, " ") {
-				return printError()
+				return newSyntaxError(input, i, "missing space between count and pattern")
 			}
 
 			//splits content into 2 parts i.e repetition count and pattern to repeat.
@@ -50,30 +115,49 @@ func decodeLine(input string) string {
 				-neither part can be empty.
 				-pattern must not contain [ or ], to avoid nested brackets.
 			*/
-			if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.ContainsAny(parts[1], "[]") {
-				return printError()
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return newSyntaxError(input, i, "count and pattern can't be empty")
+			}
+			if nested := strings.IndexByte(parts[1], '['); nested != -1 {
+				return newSyntaxError(input, i+len(parts[0])+2+nested, "nested bracket")
+			}
+
+			//colour escape sequence written as [sgr params]
+			if parts[0] == sgrPrefix {
+				sequence, ok := decodeSGR(parts[1])
+				if !ok {
+					return newSyntaxError(input, i, "invalid colour sequence")
+				}
+				visit(encodedToken{offset: i, end: end + 1, text: sequence, isSGR: true})
+				i = end + 1
+				continue
 			}
 
-			//Trying to convert repetition count to an integer. 
+			//Trying to convert repetition count to an integer, only digits are allowed ("+5" and "-5" are not counts).
 			count, err := strconv.Atoi(parts[0])
-			if err != nil || count < 0 {
-				return printError()
+			if err != nil || strings.TrimLeft(parts[0], "0123456789") != "" {
+				return newSyntaxError(input, i+1, fmt.Sprintf("invalid count %q", parts[0]))
 			}
-			//appends repeated pattern to the result.
-			result.WriteString(strings.Repeat(parts[1], count))
+			visit(encodedToken{offset: i, end: end + 1, count: count, pattern: parts[1], isRun: true})
 			i = end + 1 //adjusting index.
 		} else if input[i] == ']' {
-			return printError()
+			return newSyntaxError(input, i, "unexpected closing bracket")
 		} else {
-			//adding normal text outside of brackets to result.
-			result.WriteByte(input[i])
-			i++
+			//normal text outside of brackets, up to the next bracket.
+			end := strings.IndexAny(input[i:], "[]")
+			if end == -1 {
+				end = length
+			} else {
+				end += i
+			}
+			visit(encodedToken{offset: i, end: end, text: input[i:end]})
+			i = end
 		}
 	}
 
-	return result.String()
+	return nil
 }
 
 func printError() string {
 	return "Error\n"
-}
\ No newline at end of file
+}
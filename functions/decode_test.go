@@ -0,0 +1,57 @@
+package functions
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		input	string
+		want	string
+	}{
+		{"[3 a]b", "aaab"},
+		{"[2 ab][0 c]", "abab"},
+		{"[007 x]", "xxxxxxx"},
+		{"[2 a b]", "a ba b"},
+		{"[1 ═]╗", "═╗"},
+	}
+	for _, test := range tests {
+		got, err := Decode(test.input, false)
+		if err != nil {
+			t.Errorf("Decode(%q): %v", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Decode(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		input	string
+		line	int
+		column	int
+	}{
+		{"[3 a", 1, 1},
+		{"ab]", 1, 3},
+		{"[3a]", 1, 1},
+		{"[x a]", 1, 2},
+		{"[+5 a]", 1, 2}, // the size checks only know unsigned counts
+		{"[-5 a]", 1, 2},
+		{"[ a]", 1, 1},
+		{"ok\n══[2 [a]]", 2, 6},
+	}
+	for _, test := range tests {
+		_, err := Decode(test.input, true)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Decode(%q) error = %v, want a *SyntaxError", test.input, err)
+			continue
+		}
+		if syntaxErr.Line != test.line || syntaxErr.Column != test.column {
+			t.Errorf("Decode(%q) error at line %d, column %d, want %d, %d", test.input, syntaxErr.Line, syntaxErr.Column, test.line, test.column)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package functions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// colours of the terminal overlay.
+const (
+	overlayAdded	= "\x1b[1;32m"	// character where there was a space
+	overlayChanged	= "\x1b[1;33m"	// character replaced by another one
+	overlayRemoved	= "\x1b[1;31m"	// character replaced by a space, the old one is shown
+	overlayReset	= "\x1b[0m"
+)
+
+// unifiedContext is the number of unchanged lines shown around every hunk of a unified diff.
+const unifiedContext = 3
+
+// CellChange is one character cell that differs between two versions of the art.
+type CellChange struct {
+	Row		int // 1-based
+	Column	int // 1-based
+	Old		rune
+	New		rune
+}
+
+// ArtDiff compares two versions of the art character by character.
+type ArtDiff struct {
+	Old		[][]rune
+	New		[][]rune
+	Width	int // size of the grid covering both versions
+	Height	int
+	Changes	[]CellChange
+}
+
+// DiffCell is one character of a row in a side by side view.
+type DiffCell struct {
+	Char	string
+	Changed	bool
+}
+
+// DiffRow is a row of both versions for a side by side view.
+type DiffRow struct {
+	Number	int
+	Old		[]DiffCell
+	New		[]DiffCell
+	Changed	bool
+}
+
+/*
+	DecodeIfEncoded returns the art of text that is either plain or encoded:
+	text with brackets that decodes without errors is treated as encoded, anything else as plain art.
+*/
+func DecodeIfEncoded(text string) string {
+	if !strings.Contains(StripANSI(text), "[") {
+		return text
+	}
+	if decoded := DecodeString(text, true); decoded != "Error\n" {
+		return decoded
+	}
+	return text
+}
+
+// DiffArt compares the two versions on a grid covering both, missing cells count as spaces.
+func DiffArt(oldArt, newArt string) ArtDiff {
+	d := ArtDiff{Old: artLines(oldArt), New: artLines(newArt)}
+	oldWidth, oldHeight := artSize(d.Old)
+	newWidth, newHeight := artSize(d.New)
+	d.Width, d.Height = max(oldWidth, newWidth), max(oldHeight, newHeight)
+
+	for y := range d.Height {
+		for x := range d.Width {
+			before, after := cellAt(d.Old, x, y), cellAt(d.New, x, y)
+			if before != after {
+				d.Changes = append(d.Changes, CellChange{Row: y + 1, Column: x + 1, Old: before, New: after})
+			}
+		}
+	}
+	return d
+}
+
+// Equal reports whether both versions look the same.
+func (d ArtDiff) Equal() bool {
+	return len(d.Changes) == 0
+}
+
+// Summary returns a one line description like "5 cells changed in 2 rows".
+func (d ArtDiff) Summary() string {
+	if d.Equal() {
+		return "no changes"
+	}
+	rows := map[int]bool{}
+	for _, change := range d.Changes {
+		rows[change.Row] = true
+	}
+	return fmt.Sprintf("%d %s changed in %d %s", len(d.Changes), plural(len(d.Changes), "cell"), len(rows), plural(len(rows), "row"))
+}
+
+// plural adds an 's' to word unless n is 1.
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// CellReport lists every changed cell as "row 3, column 7: 'a' -> 'b'".
+func (d ArtDiff) CellReport() string {
+	var b strings.Builder
+	for _, change := range d.Changes {
+		fmt.Fprintf(&b, "row %d, column %d: %q -> %q\n", change.Row, change.Column, change.Old, change.New)
+	}
+	return b.String()
+}
+
+/*
+	Overlay returns the new version with the changed cells highlighted by ANSI colours:
+	- green: a character where there was a space.
+	- yellow: a character replaced by another one.
+	- red: a removed character, the old character is shown in its place.
+*/
+func (d ArtDiff) Overlay() string {
+	changed := make(map[[2]int]CellChange, len(d.Changes))
+	for _, change := range d.Changes {
+		changed[[2]int{change.Row - 1, change.Column - 1}] = change
+	}
+
+	var b strings.Builder
+	for y := range d.Height {
+		var line strings.Builder
+		for x := range d.Width {
+			change, ok := changed[[2]int{y, x}]
+			switch {
+			case !ok:
+				line.WriteRune(cellAt(d.New, x, y))
+			case change.New == ' ':
+				line.WriteString(overlayRemoved + string(change.Old) + overlayReset)
+			case change.Old == ' ':
+				line.WriteString(overlayAdded + string(change.New) + overlayReset)
+			default:
+				line.WriteString(overlayChanged + string(change.New) + overlayReset)
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Rows returns both versions row by row for a side by side view, every row padded to the grid width.
+func (d ArtDiff) Rows() []DiffRow {
+	rows := make([]DiffRow, d.Height)
+	for y := range rows {
+		row := DiffRow{Number: y + 1, Old: make([]DiffCell, d.Width), New: make([]DiffCell, d.Width)}
+		for x := range d.Width {
+			before, after := cellAt(d.Old, x, y), cellAt(d.New, x, y)
+			changed := before != after
+			row.Old[x] = DiffCell{Char: string(before), Changed: changed}
+			row.New[x] = DiffCell{Char: string(after), Changed: changed}
+			row.Changed = row.Changed || changed
+		}
+		rows[y] = row
+	}
+	return rows
+}
+
+// Unified returns a line based diff in the unified format, empty when the versions are equal.
+func (d ArtDiff) Unified(oldName, newName string) string {
+	if d.Equal() {
+		return ""
+	}
+	oldLines, newLines := runeLinesToStrings(d.Old), runeLinesToStrings(d.New)
+	ops := diffLines(oldLines, newLines)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// find the next change and the end of its hunk, changes closer than twice the context share a hunk.
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			} else if i-last > 2*unifiedContext {
+				break
+			}
+		}
+		from, to := max(first-unifiedContext, 0), min(last+unifiedContext+1, len(ops))
+		writeHunk(&b, ops[from:to])
+		start = to
+	}
+	return b.String()
+}
+
+// lineOp is one line of a line diff: ' ' unchanged, '-' removed or '+' added.
+type lineOp struct {
+	kind	byte
+	text	string
+	oldLine	int // 1-based line number in the old version, 0 for added lines
+	newLine	int // 1-based line number in the new version, 0 for removed lines
+}
+
+// maxLCSTable is the largest number of cells of a full LCS table, larger inputs are split in half first.
+const maxLCSTable = 1 << 16
+
+/*
+	diffLines computes the line operations turning a into b with a longest common subsequence.
+	common lines at the start and the end are matched directly. what is left is split in half with
+	Hirschberg's algorithm until a full table fits into maxLCSTable, so memory stays linear in the number of lines.
+*/
+func diffLines(a, b []string) []lineOp {
+	return appendLineOps(nil, a, b, 0, 0)
+}
+
+// appendLineOps appends the operations turning a into b, the line numbers start after the offsets.
+func appendLineOps(ops []lineOp, a, b []string, oldOffset, newOffset int) []lineOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, lineOp{kind: ' ', text: a[prefix], oldLine: oldOffset + prefix + 1, newLine: newOffset + prefix + 1})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	oldStart, newStart := oldOffset+prefix, newOffset+prefix
+
+	switch {
+	case len(middleA) == 0 || len(middleB) == 0:
+		ops = appendChanges(ops, middleA, middleB, oldStart, newStart)
+	case len(middleA) == 1:
+		// the line is kept when it appears in b, everything else is added around it.
+		if j := slices.Index(middleB, middleA[0]); j >= 0 {
+			ops = appendChanges(ops, nil, middleB[:j], oldStart, newStart)
+			ops = append(ops, lineOp{kind: ' ', text: middleA[0], oldLine: oldStart + 1, newLine: newStart + j + 1})
+			ops = appendChanges(ops, nil, middleB[j+1:], oldStart+1, newStart+j+1)
+		} else {
+			ops = appendChanges(ops, middleA, middleB, oldStart, newStart)
+		}
+	case (len(middleA)+1)*(len(middleB)+1) <= maxLCSTable:
+		ops = appendTableOps(ops, middleA, middleB, oldStart, newStart)
+	default:
+		// split b where the halves of a have the longest common subsequences with it.
+		half := len(middleA) / 2
+		forward := lcsPrefixLengths(middleA[:half], middleB)
+		backward := lcsSuffixLengths(middleA[half:], middleB)
+		split := 0
+		for j := range forward {
+			if forward[j]+backward[j] > forward[split]+backward[split] {
+				split = j
+			}
+		}
+		ops = appendLineOps(ops, middleA[:half], middleB[:split], oldStart, newStart)
+		ops = appendLineOps(ops, middleA[half:], middleB[split:], oldStart+half, newStart+split)
+	}
+
+	for i := len(a) - suffix; i < len(a); i++ {
+		j := i - len(a) + len(b)
+		ops = append(ops, lineOp{kind: ' ', text: a[i], oldLine: oldOffset + i + 1, newLine: newOffset + j + 1})
+	}
+	return ops
+}
+
+// appendChanges appends the lines of a as removed and the lines of b as added.
+func appendChanges(ops []lineOp, a, b []string, oldStart, newStart int) []lineOp {
+	for i, line := range a {
+		ops = append(ops, lineOp{kind: '-', text: line, oldLine: oldStart + i + 1})
+	}
+	for j, line := range b {
+		ops = append(ops, lineOp{kind: '+', text: line, newLine: newStart + j + 1})
+	}
+	return ops
+}
+
+// appendTableOps diffs small inputs with a full table of the longest common subsequences.
+func appendTableOps(ops []lineOp, a, b []string, oldStart, newStart int) []lineOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, lineOp{kind: ' ', text: a[i], oldLine: oldStart + i + 1, newLine: newStart + j + 1})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			// removed lines come before the added lines replacing them.
+			ops = append(ops, lineOp{kind: '-', text: a[i], oldLine: oldStart + i + 1})
+			i++
+		default:
+			ops = append(ops, lineOp{kind: '+', text: b[j], newLine: newStart + j + 1})
+			j++
+		}
+	}
+	return ops
+}
+
+// lcsPrefixLengths returns the lengths of the longest common subsequences of a and b[:j] for every j,
+// keeping only two rows of the table.
+func lcsPrefixLengths(a, b []string) []int {
+	previous, current := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				current[j+1] = previous[j] + 1
+			} else {
+				current[j+1] = max(previous[j+1], current[j])
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+// lcsSuffixLengths returns the lengths of the longest common subsequences of a and b[j:] for every j.
+func lcsSuffixLengths(a, b []string) []int {
+	previous, current := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				current[j] = previous[j+1] + 1
+			} else {
+				current[j] = max(previous[j], current[j+1])
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+// writeHunk writes the "@@ -a,b +c,d @@" header and the lines of one hunk.
+func writeHunk(b *strings.Builder, ops []lineOp) {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			if oldStart == 0 {
+				oldStart = op.oldLine
+			}
+			oldCount++
+		}
+		if op.kind != '-' {
+			if newStart == 0 {
+				newStart = op.newLine
+			}
+			newCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, op := range ops {
+		fmt.Fprintf(b, "%c%s\n", op.kind, op.text)
+	}
+}
+
+// runeLinesToStrings converts the grid rows back to strings.
+func runeLinesToStrings(lines [][]rune) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = string(line)
+	}
+	return result
+}
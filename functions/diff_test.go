@@ -0,0 +1,115 @@
+package functions
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestDiffArtCells(t *testing.T) {
+	tests := []struct {
+		name	string
+		old		string
+		new		string
+		want	string
+	}{
+		{"equal", "ab\ncd", "ab\ncd", "no changes"},
+		{"one cell", "ab\ncd", "ab\nce", "1 cell changed in 1 row"},
+		{"added row", "ab", "ab\ncd", "2 cells changed in 1 row"},
+		{"missing cells count as spaces", "a ", "a", "no changes"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DiffArt(test.old, test.new).Summary(); got != test.want {
+				t.Errorf("Summary() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomLines := func(n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return lines
+	}
+	tests := []struct {
+		name	string
+		a, b	[]string
+	}{
+		{"empty", nil, nil},
+		{"only added", nil, []string{"a", "b"}},
+		{"only removed", []string{"a", "b"}, nil},
+		{"one line kept", []string{"b"}, []string{"a", "b", "c"}},
+		{"one line replaced", []string{"x"}, []string{"a", "b"}},
+		{"small", randomLines(20), randomLines(30)},
+		{"split in half", randomLines(400), randomLines(500)},
+		{"long line against empty lines", []string{strings.Repeat("#", 5000)}, make([]string, 5000)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ops := diffLines(test.a, test.b)
+			checkLineOps(t, ops, test.a, test.b)
+			if len(test.a)*len(test.b) <= maxLCSTable {
+				want := appendTableOps(nil, test.a, test.b, 0, 0)
+				if kept, wantKept := keptLines(ops), keptLines(want); kept != wantKept {
+					t.Errorf("%d lines kept, the full table keeps %d", kept, wantKept)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffLinesIsMinimal(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	for range 20 {
+		a, b := make([]string, 300), make([]string, 300)
+		for i := range a {
+			a[i], b[i] = string(rune('a'+random.Intn(3))), string(rune('a'+random.Intn(3)))
+		}
+		want := lcsPrefixLengths(a, b)[len(b)]
+		if kept := keptLines(diffLines(a, b)); kept != want {
+			t.Fatalf("%d lines kept, the longest common subsequence has %d", kept, want)
+		}
+	}
+}
+
+// checkLineOps checks that the operations turn a into b with consistent line numbers.
+func checkLineOps(t *testing.T, ops []lineOp, a, b []string) {
+	t.Helper()
+	var oldLines, newLines []string
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldLines = append(oldLines, op.text)
+			if op.oldLine != len(oldLines) {
+				t.Fatalf("old line number %d, want %d", op.oldLine, len(oldLines))
+			}
+		}
+		if op.kind != '-' {
+			newLines = append(newLines, op.text)
+			if op.newLine != len(newLines) {
+				t.Fatalf("new line number %d, want %d", op.newLine, len(newLines))
+			}
+		}
+	}
+	if strings.Join(oldLines, "\n") != strings.Join(a, "\n") || len(oldLines) != len(a) {
+		t.Errorf("removed and kept lines don't give the old version")
+	}
+	if strings.Join(newLines, "\n") != strings.Join(b, "\n") || len(newLines) != len(b) {
+		t.Errorf("added and kept lines don't give the new version")
+	}
+}
+
+// keptLines counts the unchanged lines of a diff.
+func keptLines(ops []lineOp) int {
+	kept := 0
+	for _, op := range ops {
+		if op.kind == ' ' {
+			kept++
+		}
+	}
+	return kept
+}
@@ -2,12 +2,24 @@ package functions
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
+//EncodeString keeps the original behaviour of returning "Error\n" for input that can't be encoded.
 func EncodeString(input string, multiline bool) string {
-	if strings.ContainsAny(input, "[]") {
-		return "Error\n"
+	result, err := Encode(input, multiline)
+	if err != nil {
+		return printError()
+	}
+	return result
+}
+
+//Encode compresses the input, brackets are reported as a *SyntaxError with their position.
+func Encode(input string, multiline bool) (string, error) {
+	//brackets are only allowed inside colour escape sequences.
+	if err := findBracket(input); err != nil {
+		return "", err
 	}
 	if multiline {
 		lines := strings.Split(input, "\n")
@@ -15,14 +27,44 @@ func EncodeString(input string, multiline bool) string {
 		for _, line := range lines {
 			resultLines = append(resultLines, encodeLine(line))
 		}
-		return strings.Join(resultLines, "\n")
+		return strings.Join(resultLines, "\n"), nil
 	}
 	
-	return encodeLine(input)
+	return encodeLine(input), nil
+}
+
+//findBracket returns an error for the first bracket outside of a colour escape sequence.
+func findBracket(input string) *SyntaxError {
+	offset := 0
+	for _, token := range splitANSI(input) {
+		if !token.isSGR {
+			if i := strings.IndexAny(token.text, "[]"); i != -1 {
+				return newSyntaxError(input, offset+i, "brackets can't be encoded")
+			}
+		}
+		offset += len(token.text)
+	}
+	return nil
 }
 
+//encodes a line, colour escape sequences are kept as single [sgr ...] tokens so runs never split them.
 func encodeLine(line string) string {
 	var result strings.Builder
+	for _, token := range splitANSI(line) {
+		if token.isSGR {
+			result.WriteString(encodeSGR(token.text))
+		} else {
+			result.WriteString(encodeText(token.text))
+		}
+	}
+	return result.String()
+}
+
+//works on characters instead of bytes, so multi-byte characters like box drawing lines are never split.
+//the candidate patterns are compared in place, converting them to strings would allocate on every comparison.
+func encodeText(text string) string {
+	var result strings.Builder
+	line := []rune(text)
 	n := len(line)
 	i := 0
 
@@ -33,12 +75,12 @@ func encodeLine(line string) string {
 		for patternLen := 1; patternLen <= maxPatternLen; patternLen++ {
 			pattern := line[i : i+patternLen]
 			repeats := 1
-			for j := i + patternLen; j+patternLen <= n && line[j:j+patternLen] == pattern; j += patternLen {
+			for j := i + patternLen; j+patternLen <= n && slices.Equal(line[j:j+patternLen], pattern); j += patternLen {
 				repeats++
 			}
 
 			if repeats > 1 {
-				result.WriteString(fmt.Sprintf("[%d %s]", repeats, pattern))
+				result.WriteString(fmt.Sprintf("[%d %s]", repeats, string(pattern)))
 				i += repeats * patternLen
 				found = true
 				break
@@ -0,0 +1,80 @@
+package functions
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name	string
+		input	string
+		want	string
+	}{
+		{"single characters", "ab", "[1 a][1 b]"},
+		{"run", "aaaa", "[4 a]"},
+		{"pattern", "abab", "[2 ab]"},
+		{"multi-byte characters", "══╗", "[2 ═][1 ╗]"},
+		{"colour sequence", "\x1b[31maa", "[sgr 31m][2 a]"},
+		{"empty", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := Encode(test.input, true)
+			if err != nil {
+				t.Fatalf("Encode(%q): %v", test.input, err)
+			}
+			if encoded != test.want {
+				t.Errorf("Encode(%q) = %q, want %q", test.input, encoded, test.want)
+			}
+			decoded, err := Decode(encoded, true)
+			if err != nil {
+				t.Fatalf("Decode(%q): %v", encoded, err)
+			}
+			if decoded != test.input {
+				t.Errorf("Decode(Encode(%q)) = %q", test.input, decoded)
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsBrackets(t *testing.T) {
+	for _, input := range []string{"[", "a]", "ab\n[c"} {
+		if _, err := Encode(input, true); err == nil {
+			t.Errorf("Encode(%q) succeeded, want a syntax error", input)
+		}
+	}
+}
+
+//comparing the candidate patterns must not allocate, otherwise random text costs quadratic allocations.
+func TestEncodeAllocations(t *testing.T) {
+	input := randomLetters(2000)
+	allocs := testing.AllocsPerRun(3, func() {
+		EncodeString(input, false)
+	})
+	//a few allocations per encoded character for the output.
+	if limit := float64(5 * len(input)); allocs > limit {
+		t.Errorf("encoding %d random letters made %.0f allocations, want at most %.0f", len(input), allocs, limit)
+	}
+}
+
+//the encoder must stay fast on the largest input the web interface accepts, it runs on every request
+//and on every key press of the repl preview.
+func BenchmarkEncodeRandomLetters(b *testing.B) {
+	input := randomLetters(10000)
+	b.ResetTimer()
+	for range b.N {
+		EncodeString(input, false)
+	}
+}
+
+//randomLetters returns n random lowercase letters, the same ones on every run.
+func randomLetters(n int) string {
+	random := rand.New(rand.NewSource(1))
+	var letters strings.Builder
+	for range n {
+		letters.WriteByte(byte('a' + random.Intn(26)))
+	}
+	return letters.String()
+}
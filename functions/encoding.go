@@ -0,0 +1,90 @@
+package functions
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// names of the supported cyphertext encodings.
+const (
+	EncodingBase64    = "base64"
+	EncodingBase64URL = "base64url"
+	EncodingHex       = "hex"
+	EncodingBase32    = "base32"
+	EncodingRaw       = "raw" // raw bytes, only usable for file input/output
+)
+
+// Encodings lists every supported encoding in the order they are shown to users.
+var Encodings = []string{EncodingBase64, EncodingBase64URL, EncodingHex, EncodingBase32, EncodingRaw}
+
+/*
+ParseEncoding validates the encoding name given by the user.
+empty name falls back to base64 so older forms and scripts keep working.
+*/
+func ParseEncoding(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return EncodingBase64, nil
+	}
+	for _, encoding := range Encodings {
+		if name == encoding {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported encoding %q, expected one of: %s", name, strings.Join(Encodings, ", "))
+}
+
+// EncodeBytes converts binary data into text using the selected encoding.
+func EncodeBytes(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case EncodingBase64URL:
+		// no padding, so the result can be pasted into urls and filenames as is.
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	case EncodingHex:
+		return hex.EncodeToString(data), nil
+	case EncodingBase32:
+		return base32.StdEncoding.EncodeToString(data), nil
+	case EncodingRaw:
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+/*
+DecodeBytes converts text in the selected encoding back to binary data.
+whitespace is ignored for the text encodings so wrapped or pasted input still works,
+the returned error tells where the input stopped being valid.
+*/
+func DecodeBytes(input string, encoding string) ([]byte, error) {
+	if encoding != EncodingRaw {
+		input = strings.Join(strings.Fields(input), "")
+	}
+
+	var data []byte
+	var err error
+	switch encoding {
+	case EncodingBase64:
+		data, err = base64.StdEncoding.DecodeString(input)
+	case EncodingBase64URL:
+		// accept both padded and unpadded input.
+		data, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(input, "="))
+	case EncodingHex:
+		data, err = hex.DecodeString(input)
+	case EncodingBase32:
+		data, err = base32.StdEncoding.DecodeString(strings.ToUpper(input))
+	case EncodingRaw:
+		return []byte(input), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("input is not valid %s: %w", encoding, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,150 @@
+package functions
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeBytesRoundTrip(t *testing.T) {
+	data := []byte("art\x00\xff\n")
+	tests := []struct {
+		encoding	string
+		want		string
+	}{
+		{EncodingBase64, "YXJ0AP8K"},
+		{EncodingBase64URL, "YXJ0AP8K"},
+		{EncodingHex, "61727400ff0a"},
+		{EncodingBase32, "MFZHIAH7BI======"},
+		{EncodingRaw, "art\x00\xff\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.encoding, func(t *testing.T) {
+			encoded, err := EncodeBytes(data, test.encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if encoded != test.want {
+				t.Errorf("EncodeBytes = %q, want %q", encoded, test.want)
+			}
+			decoded, err := DecodeBytes(encoded, test.encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded, data) {
+				t.Errorf("DecodeBytes = %q, want %q", decoded, data)
+			}
+		})
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	tests := []struct {
+		name		string
+		input		string
+		encoding	string
+		want		string
+		wantErr		bool
+	}{
+		{"wrapped base64", "YXJ0\n AP8K", EncodingBase64, "art\x00\xff\n", false},
+		{"padded base64url", "YQ==", EncodingBase64URL, "a", false},
+		{"lowercase base32", "me======", EncodingBase32, "a", false},
+		{"raw keeps whitespace", " a\n", EncodingRaw, " a\n", false},
+		{"invalid base64", "not base64!", EncodingBase64, "", true},
+		{"odd hex", "abc", EncodingHex, "", true},
+		{"unknown encoding", "YQ==", "base58", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoded, err := DecodeBytes(test.input, test.encoding)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("DecodeBytes(%q) error = %v, want error %v", test.input, err, test.wantErr)
+			}
+			if string(decoded) != test.want {
+				t.Errorf("DecodeBytes(%q) = %q, want %q", test.input, decoded, test.want)
+			}
+		})
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		name	string
+		want	string
+		wantErr	bool
+	}{
+		{"", EncodingBase64, false},
+		{" HEX ", EncodingHex, false},
+		{"raw", EncodingRaw, false},
+		{"rot13", "", true},
+	}
+	for _, test := range tests {
+		got, err := ParseEncoding(test.name)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("ParseEncoding(%q) = %q, %v, want %q", test.name, got, err, test.want)
+		}
+	}
+}
+
+func TestXorifyWith(t *testing.T) {
+	key := []byte("key")
+	for _, encoding := range Encodings {
+		t.Run(encoding, func(t *testing.T) {
+			encrypted, err := XorifyWith("[5 #]", key, encoding, DirectionEncrypt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decrypted, err := XorifyWith(encrypted, key, encoding, DirectionDecrypt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decrypted != "[5 #]" {
+				t.Errorf("decrypted %q, want %q", decrypted, "[5 #]")
+			}
+		})
+	}
+
+	tests := []struct {
+		name		string
+		input		string
+		key			[]byte
+		direction	string
+		want		string
+		wantErr		bool
+	}{
+		{"auto encrypts text", "hi!", key, DirectionAuto, "AwxY", false},
+		{"auto decrypts base64", "AwxY", key, DirectionAuto, "hi!", false},
+		{"decrypt rejects invalid input", "hi!", key, DirectionDecrypt, "", true},
+		{"empty key", "hi!", nil, DirectionEncrypt, "", true},
+		{"unknown direction", "hi!", key, "sideways", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := XorifyWith(test.input, test.key, EncodingBase64, test.direction)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("XorifyWith error = %v, want error %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("XorifyWith = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRot13ify(t *testing.T) {
+	tests := []struct {
+		input	string
+		want	string
+	}{
+		{"Hello, World!", "Uryyb, Jbeyq!"},
+		{"══╗ art", "══╗ neg"},
+		{"\xff\xfeab", "\xff\xfeno"},
+	}
+	for _, test := range tests {
+		if got := Rot13ify(test.input); got != test.want {
+			t.Errorf("Rot13ify(%q) = %q, want %q", test.input, got, test.want)
+		}
+		if back := Rot13ify(test.want); back != test.input {
+			t.Errorf("Rot13ify(%q) = %q, want %q", test.want, back, test.input)
+		}
+	}
+}
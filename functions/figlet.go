@@ -0,0 +1,391 @@
+package functions
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bundled FIGlet fonts, usable by name.
+//
+//go:embed fonts/*.flf
+var bundledFonts embed.FS
+
+// DefaultFont is the bundled font used when no font is given.
+const DefaultFont = "segment"
+
+// layout bits of the FIGlet header (full_layout), only horizontal layout is used.
+const (
+	smushEqual		= 1
+	smushLowline	= 2
+	smushHierarchy	= 4
+	smushPair		= 8
+	smushBigX		= 16
+	smushHardblank	= 32
+	layoutKerning	= 64
+	layoutSmushing	= 128
+)
+
+// banner layouts that can override the font's own layout.
+const (
+	LayoutDefault	= ""		// whatever the font asks for
+	LayoutFull		= "full"	// every character at its full width
+	LayoutKerning	= "kern"	// characters moved together until they touch
+	LayoutSmushing	= "smush"	// characters overlap by one column where the font's rules allow
+)
+
+// Font is a parsed FIGlet font.
+type Font struct {
+	Name		string
+	Height		int
+	Hardblank	rune
+	Layout		int // full_layout bits
+	glyphs		map[rune][]string
+}
+
+// FontNames returns the names of the bundled fonts.
+func FontNames() []string {
+	entries, _ := bundledFonts.ReadDir("fonts")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".flf"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadFont returns a bundled font by name, or reads a .flf file when name is a path.
+func LoadFont(name string) (*Font, error) {
+	if name == "" {
+		name = DefaultFont
+	}
+	if file, err := bundledFonts.Open("fonts/" + name + ".flf"); err == nil {
+		defer file.Close()
+		return ParseFont(name, file)
+	}
+	if !strings.HasSuffix(name, ".flf") {
+		return nil, fmt.Errorf("unknown font %q, bundled fonts are: %s", name, strings.Join(FontNames(), ", "))
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening font: %w", err)
+	}
+	defer file.Close()
+	return ParseFont(strings.TrimSuffix(path.Base(name), ".flf"), file)
+}
+
+/*
+	ParseFont reads a FIGlet font (.flf).
+	- header: flf2a<hardblank> height baseline maxlength old_layout comment_lines [direction full_layout]
+	- followed by the comment lines and the glyphs for ASCII 32-126,
+	  the 7 German characters and optional code tagged glyphs.
+	- each glyph line ends with an endmark character, repeated on the glyph's last line.
+*/
+func ParseFont(name string, r io.Reader) (*Font, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, errors.New("font is empty")
+	}
+	header := strings.Fields(scanner.Text())
+	if len(header) < 6 || !strings.HasPrefix(header[0], "flf2a") || len(header[0]) < 6 {
+		return nil, errors.New("not a FIGlet font: invalid flf2a header")
+	}
+
+	numbers := make([]int, len(header)-1)
+	for i, field := range header[1:] {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid font header value %q", field)
+		}
+		numbers[i] = n
+	}
+	font := &Font{
+		Name:		name,
+		Height:		numbers[0],
+		Hardblank:	[]rune(header[0])[5],
+		glyphs:		make(map[rune][]string),
+	}
+	if font.Height < 1 {
+		return nil, fmt.Errorf("invalid font height %d", font.Height)
+	}
+	font.Layout = layoutFromHeader(numbers[3], numbers[5:])
+
+	for i := 0; i < numbers[4] && scanner.Scan(); i++ {
+		// skip comment lines
+	}
+
+	readGlyph := func() ([]string, error) {
+		lines := make([]string, font.Height)
+		for i := range lines {
+			if !scanner.Scan() {
+				return nil, io.ErrUnexpectedEOF
+			}
+			lines[i] = trimEndmark(scanner.Text())
+		}
+		return lines, nil
+	}
+
+	// required characters: printable ASCII followed by the German ones.
+	codes := make([]rune, 0, 102)
+	for c := rune(' '); c <= '~'; c++ {
+		codes = append(codes, c)
+	}
+	codes = append(codes, 'Ä', 'Ö', 'Ü', 'ä', 'ö', 'ü', 'ß')
+	for i, code := range codes {
+		lines, err := readGlyph()
+		if err != nil {
+			// some fonts leave the German characters out.
+			if i >= 95 {
+				return font, nil
+			}
+			return nil, fmt.Errorf("font is truncated at character %q", code)
+		}
+		font.glyphs[code] = lines
+	}
+
+	// code tagged characters: a line with the code followed by the glyph.
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		code, err := strconv.ParseInt(fields[0], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid character code %q", fields[0])
+		}
+		lines, err := readGlyph()
+		if err != nil {
+			return nil, fmt.Errorf("font is truncated at character code %d", code)
+		}
+		font.glyphs[rune(code)] = lines
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading font: %w", err)
+	}
+	return font, nil
+}
+
+// layoutFromHeader returns the full_layout bits, derived from old_layout when the header has no full_layout.
+func layoutFromHeader(oldLayout int, optional []int) int {
+	if len(optional) >= 2 {
+		return optional[1]
+	}
+	switch {
+	case oldLayout == 0:
+		return layoutKerning
+	case oldLayout < 0:
+		return 0
+	default:
+		return oldLayout&31 | layoutSmushing
+	}
+}
+
+// trimEndmark removes the endmark character (repeated or not) from the end of a glyph line.
+func trimEndmark(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return line
+	}
+	endmark := line[len(line)-1:]
+	return strings.TrimRight(line, endmark)
+}
+
+// BannerOptions controls how Banner renders text.
+type BannerOptions struct {
+	Font	string // bundled font name or path to a .flf file
+	Layout	string // one of the Layout constants, LayoutDefault uses the font's layout
+}
+
+// Banner renders text with a FIGlet font, every line of the text becomes a row of big letters.
+func Banner(text string, opts BannerOptions) (string, error) {
+	font, err := LoadFont(opts.Font)
+	if err != nil {
+		return "", err
+	}
+	return font.Render(text, opts.Layout)
+}
+
+// Render renders text with the font using the given layout.
+func (font *Font) Render(text string, layout string) (string, error) {
+	mode := font.Layout
+	switch layout {
+	case LayoutDefault:
+	case LayoutFull:
+		mode = 0
+	case LayoutKerning:
+		mode = layoutKerning
+	case LayoutSmushing:
+		mode = font.Layout&63 | layoutSmushing
+	default:
+		return "", fmt.Errorf("unknown layout %q, expected full, kern or smush", layout)
+	}
+
+	var blocks []string
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		blocks = append(blocks, font.renderLine(line, mode))
+	}
+	return strings.Join(blocks, "\n"), nil
+}
+
+// renderLine lays out the characters of one line of text next to each other.
+func (font *Font) renderLine(text string, mode int) string {
+	output := make([][]rune, font.Height)
+	previousWidth := 0
+
+	for _, r := range text {
+		glyph, ok := font.glyphs[r]
+		if !ok {
+			continue // characters missing from the font are skipped, like figlet does
+		}
+		current := make([][]rune, font.Height)
+		for i, line := range glyph {
+			current[i] = []rune(line)
+		}
+		currentWidth := len(current[0])
+
+		amount := font.smushAmount(output, current, mode, previousWidth, currentWidth)
+		for row := range output {
+			outputLen := len(output[row])
+			for k := 0; k < amount && k < len(current[row]); k++ {
+				column := outputLen - amount + k
+				if column < 0 {
+					continue
+				}
+				output[row][column] = font.smush(output[row][column], current[row][k], mode, previousWidth, currentWidth)
+			}
+			if amount < len(current[row]) {
+				output[row] = append(output[row], current[row][amount:]...)
+			}
+		}
+		previousWidth = currentWidth
+	}
+
+	lines := make([]string, font.Height)
+	for i, row := range output {
+		lines[i] = strings.TrimRight(strings.ReplaceAll(string(row), string(font.Hardblank), " "), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// smushAmount returns how many columns the current glyph can overlap the output, following figlet's rules.
+func (font *Font) smushAmount(output, current [][]rune, mode, previousWidth, currentWidth int) int {
+	if mode&(layoutSmushing|layoutKerning) == 0 || len(output[0]) == 0 {
+		return 0
+	}
+	maxSmush := currentWidth
+	for row := range output {
+		line := output[row]
+		// last visible character of the output
+		lineBoundary := len(line) - 1
+		for lineBoundary > 0 && line[lineBoundary] == ' ' {
+			lineBoundary--
+		}
+		var left rune = ' '
+		if lineBoundary >= 0 {
+			left = line[lineBoundary]
+		}
+		// first visible character of the glyph
+		charBoundary := 0
+		for charBoundary < len(current[row]) && current[row][charBoundary] == ' ' {
+			charBoundary++
+		}
+		var right rune
+		if charBoundary < len(current[row]) {
+			right = current[row][charBoundary]
+		}
+
+		amount := charBoundary + len(line) - 1 - lineBoundary
+		if left == ' ' {
+			amount++
+		} else if right != 0 && font.smush(left, right, mode, previousWidth, currentWidth) != 0 {
+			amount++
+		}
+		maxSmush = min(maxSmush, amount)
+	}
+	return maxSmush
+}
+
+// smush returns the character replacing left and right when they overlap, or 0 when they can't.
+func (font *Font) smush(left, right rune, mode, previousWidth, currentWidth int) rune {
+	if left == ' ' {
+		return right
+	}
+	if right == ' ' {
+		return left
+	}
+	if previousWidth < 2 || currentWidth < 2 || mode&layoutSmushing == 0 {
+		return 0
+	}
+
+	hardblank := font.Hardblank
+	if mode&63 == 0 {
+		// universal smushing: the later character wins, hardblanks give way.
+		if left == hardblank {
+			return right
+		}
+		if right == hardblank {
+			return left
+		}
+		return right
+	}
+
+	if mode&smushHardblank != 0 && left == hardblank && right == hardblank {
+		return left
+	}
+	if left == hardblank || right == hardblank {
+		return 0
+	}
+	if mode&smushEqual != 0 && left == right {
+		return left
+	}
+	if mode&smushLowline != 0 {
+		if left == '_' && strings.ContainsRune("|/\\[]{}()<>", right) {
+			return right
+		}
+		if right == '_' && strings.ContainsRune("|/\\[]{}()<>", left) {
+			return left
+		}
+	}
+	if mode&smushHierarchy != 0 {
+		classes := []string{"|", "/\\", "[]", "{}", "()", "<>"}
+		leftClass, rightClass := -1, -1
+		for i, class := range classes {
+			if strings.ContainsRune(class, left) {
+				leftClass = i
+			}
+			if strings.ContainsRune(class, right) {
+				rightClass = i
+			}
+		}
+		if leftClass >= 0 && rightClass >= 0 && leftClass != rightClass {
+			if leftClass > rightClass {
+				return left
+			}
+			return right
+		}
+	}
+	if mode&smushPair != 0 {
+		switch string([]rune{left, right}) {
+		case "[]", "][", "{}", "}{", "()", ")(":
+			return '|'
+		}
+	}
+	if mode&smushBigX != 0 {
+		switch string([]rune{left, right}) {
+		case "/\\":
+			return '|'
+		case "\\/":
+			return 'Y'
+		case "><":
+			return 'X'
+		}
+	}
+	return 0
+}
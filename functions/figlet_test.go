@@ -0,0 +1,225 @@
+package functions
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testFont builds a one line font with the given header, every glyph is its character twice and
+// the space is two hardblanks. extra is appended after the required characters.
+func testFont(header string, german bool, extra string) string {
+	var font strings.Builder
+	font.WriteString(header + "\ncomment line\n")
+	codes := []rune{}
+	for c := rune(' '); c <= '~'; c++ {
+		codes = append(codes, c)
+	}
+	if german {
+		codes = append(codes, 'Ä', 'Ö', 'Ü', 'ä', 'ö', 'ü', 'ß')
+	}
+	for _, c := range codes {
+		glyph := string([]rune{c, c})
+		if c == ' ' {
+			glyph = "$$"
+		}
+		// the '@' glyph would lose its characters to the '@' endmark.
+		endmark := "@@"
+		if c == '@' {
+			endmark = "##"
+		}
+		font.WriteString(glyph + endmark + "\n")
+	}
+	font.WriteString(extra)
+	return font.String()
+}
+
+func TestParseFont(t *testing.T) {
+	tests := []struct {
+		name	string
+		data	string
+		layout	int
+		glyphs	map[rune]string
+	}{
+		{"full layout", testFont("flf2a$ 1 1 4 -1 1 0 129", true, ""), 129, map[rune]string{'A': "AA", 'ß': "ßß", '@': "@@"}},
+		{"old layout kerning", testFont("flf2a$ 1 1 4 0 1", true, ""), layoutKerning, nil},
+		{"old layout full width", testFont("flf2a$ 1 1 4 -1 1", true, ""), 0, nil},
+		{"old layout smushing", testFont("flf2a$ 1 1 4 15 1", true, ""), 15 | layoutSmushing, nil},
+		{"no german characters", testFont("flf2a$ 1 1 4 -1 1", false, ""), 0, map[rune]string{'~': "~~"}},
+		{"code tagged glyphs", testFont("flf2a$ 1 1 4 -1 1", true, "0x263A smiley\n:)@@\n\n9731\n*@@\n"), 0, map[rune]string{'☺': ":)", '☃': "*"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			font, err := ParseFont("test", strings.NewReader(test.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if font.Height != 1 || font.Hardblank != '$' || font.Layout != test.layout {
+				t.Errorf("height %d, hardblank %q, layout %d, want 1, '$', %d", font.Height, font.Hardblank, font.Layout, test.layout)
+			}
+			for r, want := range test.glyphs {
+				if got := font.glyphs[r]; !slices.Equal(got, []string{want}) {
+					t.Errorf("glyph %q = %q, want %q", r, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFontRejects(t *testing.T) {
+	valid := testFont("flf2a$ 1 1 4 -1 1", true, "")
+	tests := []struct {
+		name	string
+		data	string
+		want	string
+	}{
+		{"empty", "", "font is empty"},
+		{"not a font", "[5 #]", "invalid flf2a header"},
+		{"no hardblank", "flf2a 1 1 4 -1 1", "invalid flf2a header"},
+		{"short header", "flf2a$ 1 1 4", "invalid flf2a header"},
+		{"header value", "flf2a$ 1 1 four -1 1", `invalid font header value "four"`},
+		{"height", "flf2a$ 0 1 4 -1 1", "invalid font height 0"},
+		{"truncated", valid[:strings.Index(valid, "BB@@")], `truncated at character 'B'`},
+		{"character code", valid + "smiley\n:)@@\n", `invalid character code "smiley"`},
+		{"truncated tagged glyph", valid + "0x263A\n", "truncated at character code 9786"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseFont("test", strings.NewReader(test.data))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ParseFont error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestTrimEndmark(t *testing.T) {
+	tests := []struct{ line, want string }{
+		{"|_|@", "|_|"},
+		{"|_|@@", "|_|"},
+		{" _ #\r", " _ "},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := trimEndmark(test.line); got != test.want {
+			t.Errorf("trimEndmark(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+}
+
+func TestSmush(t *testing.T) {
+	font := &Font{Hardblank: '$'}
+	tests := []struct {
+		name		string
+		left, right	rune
+		mode		int
+		want		rune
+	}{
+		{"left space", ' ', 'a', layoutKerning, 'a'},
+		{"right space", 'a', ' ', layoutKerning, 'a'},
+		{"kerning", 'a', 'b', layoutKerning, 0},
+		{"universal", 'a', 'b', layoutSmushing, 'b'},
+		{"universal left hardblank", '$', 'b', layoutSmushing, 'b'},
+		{"universal right hardblank", 'a', '$', layoutSmushing, 'a'},
+		{"equal", '|', '|', layoutSmushing | smushEqual, '|'},
+		{"equal without the rule", '|', '|', layoutSmushing | smushLowline, 0},
+		{"lowline left", '_', '/', layoutSmushing | smushLowline, '/'},
+		{"lowline right", '(', '_', layoutSmushing | smushLowline, '('},
+		{"hierarchy", '|', '/', layoutSmushing | smushHierarchy, '/'},
+		{"hierarchy left wins", '<', '[', layoutSmushing | smushHierarchy, '<'},
+		{"hierarchy same class", '/', '\\', layoutSmushing | smushHierarchy, 0},
+		{"opposite pair", '[', ']', layoutSmushing | smushPair, '|'},
+		{"opposite pair reversed", ')', '(', layoutSmushing | smushPair, '|'},
+		{"big x bar", '/', '\\', layoutSmushing | smushBigX, '|'},
+		{"big x y", '\\', '/', layoutSmushing | smushBigX, 'Y'},
+		{"big x x", '>', '<', layoutSmushing | smushBigX, 'X'},
+		{"hardblanks", '$', '$', layoutSmushing | smushHardblank, '$'},
+		{"hardblanks without the rule", '$', '$', layoutSmushing | smushEqual, 0},
+		{"hardblank and character", '$', 'a', layoutSmushing | smushEqual | smushHardblank, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := font.smush(test.left, test.right, test.mode, 2, 2); got != test.want {
+				t.Errorf("smush(%q, %q) = %q, want %q", test.left, test.right, got, test.want)
+			}
+		})
+	}
+	// characters one column wide are never smushed.
+	if got := font.smush('a', 'b', layoutSmushing, 1, 2); got != 0 {
+		t.Errorf("smush of a narrow character = %q, want 0", got)
+	}
+}
+
+func TestRenderLayouts(t *testing.T) {
+	tests := []struct {
+		header	string
+		text	string
+		layout	string
+		want	string
+	}{
+		{"flf2a$ 1 1 4 -1 1", "ab", LayoutDefault, "aabb"},
+		{"flf2a$ 1 1 4 -1 1", "ab", LayoutSmushing, "abb"},
+		{"flf2a$ 1 1 4 -1 1 0 128", "a b", LayoutDefault, "aabb"},
+		{"flf2a$ 1 1 4 -1 1 0 129", "a b", LayoutDefault, "aa  bb"},
+		{"flf2a$ 1 1 4 -1 1 0 129", "ab", LayoutDefault, "aabb"},
+		{"flf2a$ 1 1 4 -1 1 0 129", "aa", LayoutDefault, "aaa"},
+		{"flf2a$ 1 1 4 -1 1 0 129", "aa", LayoutFull, "aaaa"},
+		{"flf2a$ 1 1 4 -1 1 0 129", "a\nb", LayoutFull, "aa\nbb"},
+		{"flf2a$ 1 1 4 -1 1", "a☺b", LayoutFull, "aabb"},
+	}
+	for _, test := range tests {
+		font, err := ParseFont("test", strings.NewReader(testFont(test.header, true, "")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := font.Render(test.text, test.layout)
+		if err != nil || got != test.want {
+			t.Errorf("%s: Render(%q, %q) = %q, %v, want %q", test.header, test.text, test.layout, got, err, test.want)
+		}
+	}
+}
+
+func TestBanner(t *testing.T) {
+	if names := FontNames(); !slices.Equal(names, []string{"banner", "segment"}) {
+		t.Errorf("FontNames = %q", names)
+	}
+
+	tests := []struct {
+		font	string
+		layout	string
+		text	string
+		want	string
+	}{
+		{"segment", LayoutFull, "LOL", "   _\n| | ||\n|_|_||_"},
+		{"segment", LayoutKerning, "LOL", "   _\n| | ||\n|_|_||_"},
+		{"segment", LayoutDefault, "LOL", "  _\n|| ||\n||_||_"},
+		{"", LayoutDefault, "Hi!", "\n|_|||\n| ||o"},
+		{"banner", LayoutDefault, "Hi", strings.Join([]string{
+			"#   #  #",
+			"#   #",
+			"#   # ##",
+			"#####  #",
+			"#   #  #",
+			"#   #  #",
+			"#   # ###",
+		}, "\n")},
+	}
+	for _, test := range tests {
+		t.Run(test.font+" "+test.layout, func(t *testing.T) {
+			got, err := Banner(test.text, BannerOptions{Font: test.font, Layout: test.layout})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("Banner(%q) =\n%s\nwant\n%s", test.text, got, test.want)
+			}
+		})
+	}
+
+	if _, err := Banner("hi", BannerOptions{Font: "comic"}); err == nil || !strings.Contains(err.Error(), "banner, segment") {
+		t.Errorf("unknown font: %v, want an error listing the bundled fonts", err)
+	}
+	if _, err := Banner("hi", BannerOptions{Layout: "wide"}); err == nil {
+		t.Error("unknown layout succeeded, want an error")
+	}
+}
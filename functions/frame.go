@@ -0,0 +1,139 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// border characters of a frame style.
+type borderStyle struct {
+	topLeft, top, topRight	rune
+	left, right				rune
+	bottomLeft, bottomRight	rune
+	bottom					rune
+}
+
+// frameStyles maps the style names to their borders, none of them uses brackets so framed art still encodes.
+var frameStyles = map[string]borderStyle{
+	"ascii":	{'+', '-', '+', '|', '|', '+', '+', '-'},
+	"single":	{'┌', '─', '┐', '│', '│', '└', '┘', '─'},
+	"double":	{'╔', '═', '╗', '║', '║', '╚', '╝', '═'},
+	"rounded":	{'╭', '─', '╮', '│', '│', '╰', '╯', '─'},
+}
+
+// DefaultFrameStyle is used when no style is given.
+const DefaultFrameStyle = "ascii"
+
+// FrameOptions controls how art is framed.
+type FrameOptions struct {
+	Style		string // one of FrameStyleNames
+	Padding		int    // spaces between the border and the art, on every side
+	Title		string // shown in the top border
+	Align		string // alignment of the lines: AlignStart, AlignCenter or AlignEnd
+	TitleAlign	string // alignment of the title in the top border
+}
+
+// FrameStyleNames returns the available frame styles in alphabetical order.
+func FrameStyleNames() []string {
+	names := make([]string, 0, len(frameStyles))
+	for name := range frameStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+	FrameArt draws a border around decoded art or text.
+	- every line is aligned within the frame, the frame is as wide as the widest line or the title.
+	- colour escape sequences are kept and don't count towards the width.
+	- text with brackets is rejected, the result always has to be encodable.
+	the result has no final newline, like the other compositing operations.
+*/
+func FrameArt(art string, opts FrameOptions) (string, error) {
+	style, ok := frameStyles[opts.Style]
+	if opts.Style == "" {
+		style, ok = frameStyles[DefaultFrameStyle], true
+	}
+	if !ok {
+		return "", fmt.Errorf("unknown frame style %q, expected one of: %s", opts.Style, strings.Join(FrameStyleNames(), ", "))
+	}
+	if opts.Padding < 0 {
+		return "", errors.New("padding can't be negative")
+	}
+	if err := checkCompose(0, defaultAlign(opts.Align)); err != nil {
+		return "", err
+	}
+	if err := checkCompose(0, defaultAlign(opts.TitleAlign)); err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(opts.Title, "\n\r") {
+		return "", errors.New("the title must be a single line")
+	}
+	if strings.ContainsAny(StripANSI(art)+opts.Title, "[]") {
+		return "", errors.New("brackets can't be encoded, remove them from the text and the title")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, visibleWidth(line))
+	}
+	title := ""
+	if opts.Title != "" {
+		title = " " + opts.Title + " "
+		// keep at least one border character on each side of the title.
+		width = max(width, utf8.RuneCountInString(title)+2-2*opts.Padding)
+	}
+	inner := width + 2*opts.Padding
+
+	var b strings.Builder
+	// top border with the title
+	top := []rune(strings.Repeat(string(style.top), inner))
+	if title != "" {
+		start := alignOffset(inner-2, utf8.RuneCountInString(title), defaultAlign(opts.TitleAlign)) + 1
+		copy(top[start:], []rune(title))
+	}
+	b.WriteRune(style.topLeft)
+	b.WriteString(string(top))
+	b.WriteRune(style.topRight)
+	b.WriteByte('\n')
+
+	empty := string(style.left) + strings.Repeat(" ", inner) + string(style.right) + "\n"
+	for range opts.Padding {
+		b.WriteString(empty)
+	}
+	for _, line := range lines {
+		left := alignOffset(width, visibleWidth(line), defaultAlign(opts.Align))
+		b.WriteRune(style.left)
+		b.WriteString(strings.Repeat(" ", opts.Padding+left))
+		b.WriteString(line)
+		b.WriteString(strings.Repeat(" ", width-left-visibleWidth(line)+opts.Padding))
+		b.WriteRune(style.right)
+		b.WriteByte('\n')
+	}
+	for range opts.Padding {
+		b.WriteString(empty)
+	}
+
+	b.WriteRune(style.bottomLeft)
+	b.WriteString(strings.Repeat(string(style.bottom), inner))
+	b.WriteRune(style.bottomRight)
+	return b.String(), nil
+}
+
+// defaultAlign returns the alignment, AlignStart when none is given.
+func defaultAlign(align string) string {
+	if align == "" {
+		return AlignStart
+	}
+	return align
+}
+
+// visibleWidth returns the number of characters of the line without colour escape sequences.
+func visibleWidth(line string) int {
+	return utf8.RuneCountInString(StripANSI(line))
+}
@@ -0,0 +1,230 @@
+package functions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registers the decoders used by image.Decode
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// DefaultRamp lists characters from the darkest to the lightest pixel.
+const DefaultRamp = "@%#*+=-:. "
+
+// maxImagePixels limits the size of images accepted by ConvertImage.
+const maxImagePixels = 4096 * 4096
+
+// limits of the converted art, checked before the image is sampled.
+const (
+	MaxConvertColumns	= 1000
+	MaxConvertRows		= 1000
+)
+
+// ConvertOptions controls how an image is turned into art.
+type ConvertOptions struct {
+	Width		int     // characters per line
+	Ramp		string  // characters from darkest to lightest
+	CellAspect	float64 // height of a character cell divided by its width
+	Dither		bool    // spreads the rounding error to neighbouring cells (Floyd-Steinberg)
+	Edges		bool    // draws strong edges with line characters (- | / \)
+	Invert		bool    // for light text on dark backgrounds
+	MaxSize		int     // largest result in characters (one line ending per row), 0 for no limit
+}
+
+// DefaultConvertOptions returns 80 characters wide art with the default ramp.
+func DefaultConvertOptions() ConvertOptions {
+	return ConvertOptions{
+		Width:		80,
+		Ramp:		DefaultRamp,
+		CellAspect:	2,
+	}
+}
+
+// ConvertImage decodes a PNG, JPEG or GIF image and converts it into art.
+func ConvertImage(r io.Reader, opts ConvertOptions) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("error reading image: %w", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("unsupported image, expected PNG, JPEG or GIF: %w", err)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return "", fmt.Errorf("image is too large: %dx%d pixels", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("error decoding image: %w", err)
+	}
+	return ImageToArt(img, opts)
+}
+
+/*
+	ImageToArt converts an image into art.
+	- the image is scaled to opts.Width characters, rows are corrected for the cell aspect ratio.
+	- every cell gets the average luminance of the pixels it covers.
+	- luminance is mapped to the ramp, optionally with dithering.
+	- with edge detection, strong edges are drawn with line characters.
+	the ramp can't contain '[' or ']', so the result can always be encoded.
+	the size of the art is computed first, art beyond MaxConvertColumns, MaxConvertRows or opts.MaxSize is refused.
+*/
+func ImageToArt(img image.Image, opts ConvertOptions) (string, error) {
+	ramp := []rune(opts.Ramp)
+	if len(ramp) < 2 {
+		return "", errors.New("ramp needs at least two characters")
+	}
+	if strings.ContainsAny(opts.Ramp, "[]\n") {
+		return "", errors.New("ramp can't contain '[', ']' or newlines")
+	}
+	if opts.Width < 1 || opts.Width > MaxConvertColumns {
+		return "", fmt.Errorf("width must be between 1 and %d", MaxConvertColumns)
+	}
+	if opts.CellAspect <= 0 {
+		opts.CellAspect = 2
+	}
+	if opts.Invert {
+		ramp = []rune(reverseRunes(string(ramp)))
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return "", errors.New("image is empty")
+	}
+	columns := opts.Width
+	height := math.Round(float64(bounds.Dy()) / float64(bounds.Dx()) * float64(columns) / opts.CellAspect)
+	if height > MaxConvertRows || opts.MaxSize > 0 && (float64(columns)+1)*height > float64(opts.MaxSize) {
+		return "", fmt.Errorf("the art would be %d columns by %.0f rows, that is too large", columns, height)
+	}
+	rows := max(int(height), 1)
+
+	grid := luminanceGrid(img, columns, rows)
+	var edges [][]rune
+	if opts.Edges {
+		edges = detectEdges(grid)
+	}
+	levels := quantize(grid, len(ramp), opts.Dither)
+
+	var b strings.Builder
+	for y := 0; y < rows; y++ {
+		line := make([]rune, columns)
+		for x := 0; x < columns; x++ {
+			line[x] = ramp[levels[y][x]]
+			if edges != nil && edges[y][x] != 0 {
+				line[x] = edges[y][x]
+			}
+		}
+		b.WriteString(strings.TrimRight(string(line), " "))
+		if y < rows-1 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// luminanceGrid averages the luminance (0 black, 1 white) of the pixels covered by each cell.
+// transparent pixels count as white.
+func luminanceGrid(img image.Image, columns, rows int) [][]float64 {
+	bounds := img.Bounds()
+	grid := make([][]float64, rows)
+	for y := 0; y < rows; y++ {
+		grid[y] = make([]float64, columns)
+		top := bounds.Min.Y + y*bounds.Dy()/rows
+		bottom := max(bounds.Min.Y+(y+1)*bounds.Dy()/rows, top+1)
+		for x := 0; x < columns; x++ {
+			left := bounds.Min.X + x*bounds.Dx()/columns
+			right := max(bounds.Min.X+(x+1)*bounds.Dx()/columns, left+1)
+
+			sum, count := 0.0, 0
+			for py := top; py < bottom; py++ {
+				for px := left; px < right; px++ {
+					r, g, b, a := img.At(px, py).RGBA()
+					// r, g and b are premultiplied by alpha, add the white background.
+					white := float64(0xffff - a)
+					luminance := 0.2126*(float64(r)+white) + 0.7152*(float64(g)+white) + 0.0722*(float64(b)+white)
+					sum += luminance / 0xffff
+					count++
+				}
+			}
+			grid[y][x] = sum / float64(count)
+		}
+	}
+	return grid
+}
+
+// quantize maps luminance to ramp indexes, dithering spreads the error with Floyd-Steinberg weights.
+func quantize(grid [][]float64, levels int, dither bool) [][]int {
+	rows, columns := len(grid), len(grid[0])
+	values := make([][]float64, rows)
+	for y := range grid {
+		values[y] = append([]float64(nil), grid[y]...)
+	}
+
+	result := make([][]int, rows)
+	steps := float64(levels - 1)
+	for y := 0; y < rows; y++ {
+		result[y] = make([]int, columns)
+		for x := 0; x < columns; x++ {
+			value := math.Min(math.Max(values[y][x], 0), 1)
+			level := int(math.Round(value * steps))
+			result[y][x] = level
+			if !dither {
+				continue
+			}
+			diff := values[y][x] - float64(level)/steps
+			spread := func(dx, dy int, weight float64) {
+				if x+dx >= 0 && x+dx < columns && y+dy < rows {
+					values[y+dy][x+dx] += diff * weight
+				}
+			}
+			spread(1, 0, 7.0/16)
+			spread(-1, 1, 3.0/16)
+			spread(0, 1, 5.0/16)
+			spread(1, 1, 1.0/16)
+		}
+	}
+	return result
+}
+
+// edgeThreshold is the Sobel gradient magnitude above which a cell is drawn as an edge.
+const edgeThreshold = 1.2
+
+// detectEdges runs a Sobel filter over the grid, returning a line character for edge cells and 0 elsewhere.
+func detectEdges(grid [][]float64) [][]rune {
+	rows, columns := len(grid), len(grid[0])
+	at := func(x, y int) float64 {
+		x = min(max(x, 0), columns-1)
+		y = min(max(y, 0), rows-1)
+		return grid[y][x]
+	}
+
+	edges := make([][]rune, rows)
+	for y := 0; y < rows; y++ {
+		edges[y] = make([]rune, columns)
+		for x := 0; x < columns; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			if math.Hypot(gx, gy) < edgeThreshold {
+				continue
+			}
+			// the edge runs perpendicular to the gradient, y grows downwards.
+			angle := math.Mod(math.Atan2(gy, gx)*180/math.Pi+180, 180)
+			switch {
+			case angle < 22.5 || angle >= 157.5:
+				edges[y][x] = '|'
+			case angle < 67.5:
+				edges[y][x] = '/'
+			case angle < 112.5:
+				edges[y][x] = '-'
+			default:
+				edges[y][x] = '\\'
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,81 @@
+package functions
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// gradient returns an image getting lighter from left to right.
+func gradient(width, height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetGray(x, y, color.Gray{uint8(x * 255 / max(width-1, 1))})
+		}
+	}
+	return img
+}
+
+func TestImageToArt(t *testing.T) {
+	opts := DefaultConvertOptions()
+	opts.Width = 10
+	art, err := ImageToArt(gradient(100, 20), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20 pixels high at 10 columns for 100 pixels are 2 cells, halved for the tall cells.
+	lines := strings.Split(art, "\n")
+	if len(lines) != 1 {
+		t.Errorf("%d rows, want 1:\n%s", len(lines), art)
+	}
+	if !strings.HasPrefix(lines[0], "@") {
+		t.Errorf("the dark left side should start with the darkest character: %q", lines[0])
+	}
+	if _, err := Encode(art, true); err != nil {
+		t.Errorf("the art can't be encoded: %v", err)
+	}
+}
+
+func TestImageToArtLimits(t *testing.T) {
+	tests := []struct {
+		name	string
+		img		image.Image
+		width	int
+		maxSize	int
+		wantErr	bool
+	}{
+		{"small", gradient(40, 40), 20, 0, false},
+		{"tall and narrow", gradient(1, 4096), 120, 0, true},
+		{"within the size limit", gradient(40, 40), 20, 10000, false},
+		{"beyond the size limit", gradient(10, 400), 120, 10000, true},
+		{"too wide", gradient(40, 40), MaxConvertColumns + 1, 0, true},
+		{"no width", gradient(40, 40), 0, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultConvertOptions()
+			opts.Width, opts.MaxSize = test.width, test.maxSize
+			_, err := ImageToArt(test.img, opts)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ImageToArt: error %v, want an error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gradient(64, 64)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConvertImage(&buf, DefaultConvertOptions()); err != nil {
+		t.Errorf("ConvertImage: %v", err)
+	}
+	if _, err := ConvertImage(strings.NewReader("not an image"), DefaultConvertOptions()); err == nil {
+		t.Error("ConvertImage accepted text")
+	}
+}
@@ -0,0 +1,139 @@
+package functions
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLargestRuns is the number of runs listed in ArtStats.LargestRuns.
+const maxLargestRuns = 5
+
+// ArtStats describes a piece of art or encoded art, computed without expanding the runs.
+type ArtStats struct {
+	Encoded		bool
+	Format		TextFormat // line endings, final newline and BOM, the decoded art has the same layout
+	Size		int   // bytes of the input
+	DecodedSize	int64 // bytes of the decoded art, saturates at math.MaxInt64
+	Rows		int
+	Width		int64 // widest line in characters, colour sequences don't count
+	RaggedLines	int   // lines narrower than the widest one
+	Runs		int
+	LargestRuns	[]RunStat // runs with the largest counts, largest first
+	Histogram	[]CharCount // characters of the decoded art, most frequent first
+	Entropy		float64 // bits per character of the decoded art
+}
+
+// RunStat is one run of encoded art and where it is.
+type RunStat struct {
+	Line	int
+	Column	int
+	Count	int
+	Pattern	string
+}
+
+// CharCount is how often a character appears in the decoded art.
+type CharCount struct {
+	Char	rune
+	Count	int64
+}
+
+/*
+	InspectArt computes the statistics of text, which is treated as encoded art when it has brackets and is well formed.
+	runs are never expanded, so files that would decode to gigabytes are measured safely.
+	malformed encoded art (brackets that don't parse) is returned as a *SyntaxError, plain art can't have brackets.
+*/
+func InspectArt(text string) (ArtStats, error) {
+	stats := ArtStats{Size: len(text), Format: DetectTextFormat(text)}
+	text = NormalizeText(text)
+	stats.Encoded = strings.Contains(StripANSI(text), "[")
+	histogram := map[rune]int64{}
+	var widths []int64
+	var runs []RunStat
+
+	for i, line := range strings.Split(text, "\n") {
+		var width int64
+		count := func(s string, times int64) {
+			for _, r := range StripANSI(s) {
+				histogram[r] = saturatingAdd(histogram[r], times)
+				width = saturatingAdd(width, times)
+			}
+			stats.DecodedSize = saturatingAdd(stats.DecodedSize, saturatingMul(int64(len(s)), times))
+		}
+
+		if !stats.Encoded || isFrameMarker(line) {
+			count(line, 1)
+		} else {
+			err := scanEncodedLine(line, func(token encodedToken) {
+				if !token.isRun {
+					count(token.text, 1)
+					return
+				}
+				count(token.pattern, int64(token.count))
+				column := utf8.RuneCountInString(line[:token.offset]) + 1
+				runs = append(runs, RunStat{Line: i + 1, Column: column, Count: token.count, Pattern: token.pattern})
+			})
+			if err != nil {
+				err.Line = i + 1
+				return ArtStats{}, err
+			}
+		}
+		widths = append(widths, width)
+		stats.Width = max(stats.Width, width)
+	}
+	// the line endings and the byte order mark.
+	stats.DecodedSize = saturatingAdd(stats.DecodedSize, int64(len(stats.Format.Apply(strings.Repeat("\n", len(widths)-1)))))
+
+	stats.Rows = len(widths)
+	for _, width := range widths {
+		if width < stats.Width {
+			stats.RaggedLines++
+		}
+	}
+
+	stats.Runs = len(runs)
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Count > runs[j].Count
+	})
+	stats.LargestRuns = runs[:min(len(runs), maxLargestRuns)]
+
+	var total int64
+	for char, n := range histogram {
+		stats.Histogram = append(stats.Histogram, CharCount{Char: char, Count: n})
+		total = saturatingAdd(total, n)
+	}
+	sort.Slice(stats.Histogram, func(i, j int) bool {
+		a, b := stats.Histogram[i], stats.Histogram[j]
+		return a.Count > b.Count || a.Count == b.Count && a.Char < b.Char
+	})
+	for _, char := range stats.Histogram {
+		p := float64(char.Count) / float64(total)
+		stats.Entropy -= p * math.Log2(p)
+	}
+	return stats, nil
+}
+
+// CompressionRatio returns the size of the input divided by the decoded size, 1 for plain art.
+func (stats ArtStats) CompressionRatio() float64 {
+	if stats.DecodedSize == 0 {
+		return 1
+	}
+	return float64(stats.Size) / float64(stats.DecodedSize)
+}
+
+// saturatingAdd adds non-negative numbers, stopping at math.MaxInt64 instead of overflowing.
+func saturatingAdd(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// saturatingMul multiplies non-negative numbers, stopping at math.MaxInt64 instead of overflowing.
+func saturatingMul(a, b int64) int64 {
+	if a != 0 && b > math.MaxInt64/a {
+		return math.MaxInt64
+	}
+	return a * b
+}
@@ -0,0 +1,104 @@
+package functions
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestInspectArt(t *testing.T) {
+	tests := []struct {
+		name		string
+		text		string
+		decodedSize	int64
+		rows		int
+		width		int64
+		ragged		int
+	}{
+		{"plain", "##\n#", 4, 2, 2, 1},
+		{"encoded", "[3 #]\n[2 ab]", 8, 2, 4, 1},
+		{"colour sequences have no width", "[sgr 31m][2 #]", 7, 1, 2, 0},
+		{"final newline", "[3 #]\n", 4, 1, 3, 0},
+		{"crlf", "[2 #]\r\n[2 #]\r\n", 8, 2, 2, 0},
+		{"multi-byte characters", "[3 ═]", 9, 1, 3, 0},
+		{"saturates", "[9223372036854775807 ab]", math.MaxInt64, 1, math.MaxInt64, 0},
+		{"saturates across runs", "[9223372036854775807 a][9223372036854775807 a]", math.MaxInt64, 1, math.MaxInt64, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats, err := InspectArt(test.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.DecodedSize != test.decodedSize {
+				t.Errorf("DecodedSize = %d, want %d", stats.DecodedSize, test.decodedSize)
+			}
+			if stats.Rows != test.rows || stats.Width != test.width || stats.RaggedLines != test.ragged {
+				t.Errorf("Rows, Width, RaggedLines = %d, %d, %d, want %d, %d, %d",
+					stats.Rows, stats.Width, stats.RaggedLines, test.rows, test.width, test.ragged)
+			}
+			// the decoded size must match the decoder wherever decoding is feasible.
+			if test.decodedSize < 1000 {
+				decoded, err := Decode(test.text, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if int64(len(decoded)) != stats.DecodedSize {
+					t.Errorf("DecodedSize = %d, the decoder returns %d bytes", stats.DecodedSize, len(decoded))
+				}
+			}
+		})
+	}
+}
+
+func TestInspectArtLargestRuns(t *testing.T) {
+	stats, err := InspectArt("[2 a][9 b][5 c]\n[9 d][1 e][7 f][3 g]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RunStat{
+		{Line: 1, Column: 6, Count: 9, Pattern: "b"},
+		{Line: 2, Column: 1, Count: 9, Pattern: "d"}, // equal counts keep their order
+		{Line: 2, Column: 11, Count: 7, Pattern: "f"},
+		{Line: 1, Column: 11, Count: 5, Pattern: "c"},
+		{Line: 2, Column: 16, Count: 3, Pattern: "g"},
+	}
+	if stats.Runs != 7 {
+		t.Errorf("Runs = %d, want 7", stats.Runs)
+	}
+	if !slices.Equal(stats.LargestRuns, want) {
+		t.Errorf("LargestRuns = %v, want %v", stats.LargestRuns, want)
+	}
+}
+
+func TestInspectArtEntropy(t *testing.T) {
+	tests := []struct {
+		text	string
+		want	float64
+	}{
+		{"[8 a]", 0},
+		{"abcd", 2},
+		{"[4 abcdefgh]", 3},
+	}
+	for _, test := range tests {
+		stats, err := InspectArt(test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(stats.Entropy-test.want) > 1e-9 {
+			t.Errorf("Entropy of %q = %f, want %f", test.text, stats.Entropy, test.want)
+		}
+	}
+}
+
+func TestInspectArtSyntaxError(t *testing.T) {
+	_, err := InspectArt("[3 a]\n[2 b]\nab[4 c")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("InspectArt error = %v, want a *SyntaxError", err)
+	}
+	if syntaxErr.Line != 3 || syntaxErr.Column != 3 {
+		t.Errorf("error at line %d, column %d, want line 3, column 3", syntaxErr.Line, syntaxErr.Column)
+	}
+}
@@ -0,0 +1,179 @@
+package functions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LintIssue is a construct of encoded art that decodes fine but isn't minimal.
+type LintIssue struct {
+	Line	int // 1-based
+	Column	int // 1-based, counted in characters
+	Msg		string
+}
+
+func (issue LintIssue) String() string {
+	return fmt.Sprintf("line %d, column %d: %s", issue.Line, issue.Column, issue.Msg)
+}
+
+/*
+	Lint reports the constructs of the encoded art that could be written shorter:
+	- runs repeated zero times, like [0 x].
+	- runs of one, like [1 x].
+	- adjacent runs of the same pattern, like [3 a][2 a].
+	- runs whose expansion is shorter than the brackets, like [2 a].
+	malformed input is returned as a *SyntaxError, only the first issue of a run is reported.
+*/
+func Lint(encoded string) ([]LintIssue, error) {
+	if _, err := Decode(encoded, true); err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	for i, line := range strings.Split(encoded, "\n") {
+		if isFrameMarker(line) {
+			continue
+		}
+		var previous *encodedToken
+		for _, run := range encodedRuns(line) {
+			if msg := lintRun(&run, previous); msg != "" {
+				column := utf8.RuneCountInString(line[:run.offset]) + 1
+				issues = append(issues, LintIssue{Line: i + 1, Column: column, Msg: msg})
+			}
+			previous = &run
+		}
+	}
+	return issues, nil
+}
+
+// lintRun returns the issue of the run, previous is the run right before it (nil when there's text in between).
+func lintRun(run, previous *encodedToken) string {
+	bracket := runText(run.count, run.pattern)
+	expanded := strings.Repeat(run.pattern, run.count)
+	switch {
+	case run.count == 0:
+		return fmt.Sprintf("%s expands to nothing, remove it", bracket)
+	case run.count == 1:
+		return fmt.Sprintf("%s repeats once, write %s instead", bracket, quoteText(run.pattern, '"'))
+	case previous != nil && previous.end == run.offset && previous.pattern == run.pattern:
+		return fmt.Sprintf("%s follows a run of the same pattern, merge them into %s",
+			bracket, runText(previous.count+run.count, run.pattern))
+	case len(expanded) < len(bracket):
+		return fmt.Sprintf("%s is longer than its expansion, write %s instead", bracket, quoteText(expanded, '"'))
+	}
+	return ""
+}
+
+/*
+	quoteText puts text in quotes the way users write it in their art: unlike %q, backslashes and quotes
+	are shown as they are, only characters that can't be seen are escaped (e.g. a tab as \t).
+*/
+func quoteText(text string, quote rune) string {
+	var b strings.Builder
+	b.WriteRune(quote)
+	for _, r := range text {
+		if unicode.IsPrint(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteString(strings.Trim(strconv.QuoteRune(r), "'"))
+		}
+	}
+	b.WriteRune(quote)
+	return b.String()
+}
+
+// encodedRuns returns the runs of a line that decodes without errors, colour sequences are skipped.
+func encodedRuns(line string) []encodedToken {
+	var runs []encodedToken
+	scanEncodedLine(line, func(token encodedToken) {
+		if token.isRun {
+			runs = append(runs, token)
+		}
+	})
+	return runs
+}
+
+// runText writes a run in bracket form.
+func runText(count int, pattern string) string {
+	return "[" + strconv.Itoa(count) + " " + pattern + "]"
+}
+
+/*
+	Canonical rewrites the encoded art in its canonical minimal form:
+	every line is decoded and encoded again with the shortest mix of plain text and runs,
+	ties are written as plain text. the decoded art stays exactly the same, frame markers are kept.
+*/
+func Canonical(encoded string) (string, error) {
+	decoded, err := Decode(encoded, true)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(decoded, "\n")
+	for i, line := range lines {
+		if isFrameMarker(line) {
+			continue
+		}
+		var b strings.Builder
+		for _, token := range splitANSI(line) {
+			if token.isSGR {
+				b.WriteString(encodeSGR(token.text))
+			} else {
+				b.WriteString(minimalEncoding(token.text))
+			}
+		}
+		lines[i] = b.String()
+	}
+	canonical := strings.Join(lines, "\n")
+
+	// a safety net, the rewrite must never change the art.
+	if check, err := Decode(canonical, true); err != nil || check != decoded {
+		return "", fmt.Errorf("canonical form doesn't decode to the same art")
+	}
+	return canonical, nil
+}
+
+/*
+	minimalEncoding returns the shortest encoding of text, found by dynamic programming from the end:
+	cost[i] is the length of the shortest encoding of text[i:], either the character as plain text
+	or any run of a pattern starting at i followed by the rest.
+*/
+func minimalEncoding(text string) string {
+	chars := []rune(text)
+	n := len(chars)
+	cost := make([]int, n+1)
+	// choice[i] is the run starting at i, a count of 0 writes the character as plain text.
+	type run struct{ count, patternLen int }
+	choice := make([]run, n)
+
+	for i := n - 1; i >= 0; i-- {
+		cost[i] = cost[i+1] + utf8.RuneLen(chars[i])
+		for patternLen := 1; i+2*patternLen <= n; patternLen++ {
+			pattern := string(chars[i : i+patternLen])
+			for count := 2; i+count*patternLen <= n; count++ {
+				start := i + (count-1)*patternLen
+				if string(chars[start:start+patternLen]) != pattern {
+					break
+				}
+				if c := len(runText(count, pattern)) + cost[i+count*patternLen]; c < cost[i] {
+					cost[i] = c
+					choice[i] = run{count, patternLen}
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < n; {
+		if choice[i].count == 0 {
+			b.WriteRune(chars[i])
+			i++
+			continue
+		}
+		b.WriteString(runText(choice[i].count, string(chars[i:i+choice[i].patternLen])))
+		i += choice[i].count * choice[i].patternLen
+	}
+	return b.String()
+}
@@ -0,0 +1,92 @@
+package functions
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name		string
+		encoded		string
+		issues		[]LintIssue
+	}{
+		{"minimal", "[5 #] /\\", nil},
+		{"zero runs", "a[0 x]b", []LintIssue{{Line: 1, Column: 2, Msg: `[0 x] expands to nothing, remove it`}}},
+		{"runs of one", "[1 ab]", []LintIssue{{Line: 1, Column: 1, Msg: `[1 ab] repeats once, write "ab" instead`}}},
+		{"adjacent runs", "\n[6 a][4 a]", []LintIssue{{Line: 2, Column: 6, Msg: `[4 a] follows a run of the same pattern, merge them into [10 a]`}}},
+		{"longer than expansion", "[2 a]", []LintIssue{{Line: 1, Column: 1, Msg: `[2 a] is longer than its expansion, write "aa" instead`}}},
+		{"frame markers are skipped", "@frame 100\n[5 #]", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues, err := Lint(test.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(issues, test.issues) {
+				t.Errorf("Lint(%q) = %v, want %v", test.encoded, issues, test.issues)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		encoded	string
+		want	string
+	}{
+		{"[12 #]", "[12 #]"},
+		{"[5 #]", "#####"},
+		{"[8 a][4 a]b", "[12 a]b"},
+		{"[1 x][0 y]", "x"},
+		{"[2 a]", "aa"},
+		{"abababab", "[4 ab]"},
+		{"[sgr 1m][12 #]", "[sgr 1m][12 #]"},
+		{"@frame 100\n[1 #]", "@frame 100\n#"},
+	}
+	for _, test := range tests {
+		canonical, err := Canonical(test.encoded)
+		if err != nil {
+			t.Errorf("Canonical(%q): %v", test.encoded, err)
+			continue
+		}
+		if canonical != test.want {
+			t.Errorf("Canonical(%q) = %q, want %q", test.encoded, canonical, test.want)
+		}
+	}
+}
+
+func TestLintRejectsMalformedInput(t *testing.T) {
+	for _, encoded := range []string{"[5 #", "[x #]", "[5]"} {
+		var syntaxErr *SyntaxError
+		if _, err := Lint(encoded); !errors.As(err, &syntaxErr) {
+			t.Errorf("Lint(%q) error = %v, want a *SyntaxError", encoded, err)
+		}
+		if _, err := Canonical(encoded); err == nil {
+			t.Errorf("Canonical(%q) succeeded, want an error", encoded)
+		}
+	}
+}
+
+// backslashes are common in art, lint messages show them as written instead of escaped.
+func TestLintQuoting(t *testing.T) {
+	tests := []struct {
+		encoded	string
+		want	string
+	}{
+		{"[1 \\]", `[1 \] repeats once, write "\" instead`},
+		{"[2 /\\]", `[2 /\] is longer than its expansion, write "/\/\" instead`},
+		{"[1 \t]", `[1 	] repeats once, write "\t" instead`},
+	}
+	for _, test := range tests {
+		issues, err := Lint(test.encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 1 || issues[0].Msg != test.want {
+			t.Errorf("Lint(%q) = %v, want %s", test.encoded, issues, test.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// PipelineOptions holds the settings shared by every step of a pipeline.
+type PipelineOptions struct {
+	Key			[]byte // key for the xor step
+	MultiLine	bool   // art steps encode/decode line by line
+
+	// BeforeStep is called with the step name and its input before the step runs,
+	// returning an error stops the pipeline (used by the server to enforce limits).
+	BeforeStep	func(step, input string) error
+}
+
+// pipelineStep describes a single transform that can be used in a pipeline.
+type pipelineStep struct {
+	run		func(input string, opts PipelineOptions) (string, error)
+	inverse	string // name of the step undoing this one, empty if it can't be undone
+}
+
+// pipelineSteps maps every step name to its transform.
+var pipelineSteps = map[string]pipelineStep{
+	"encode":		{run: encodeStep, inverse: "decode"},
+	"decode":		{run: decodeStep, inverse: "encode"},
+	"xor":			{run: xorStep, inverse: "xor"},
+	"rot13":		{run: func(input string, _ PipelineOptions) (string, error) { return Rot13ify(input), nil }, inverse: "rot13"},
+	"reverse":		{run: func(input string, _ PipelineOptions) (string, error) { return reverseRunes(input), nil }, inverse: "reverse"},
+	"upper":		{run: func(input string, _ PipelineOptions) (string, error) { return strings.ToUpper(input), nil }},
+	"lower":		{run: func(input string, _ PipelineOptions) (string, error) { return strings.ToLower(input), nil }},
+	"strip":		{run: func(input string, _ PipelineOptions) (string, error) { return StripANSI(input), nil }},
+	"base64":		encodingStep(EncodingBase64),
+	"unbase64":		decodingStep(EncodingBase64),
+	"base64url":	encodingStep(EncodingBase64URL),
+	"unbase64url":	decodingStep(EncodingBase64URL),
+	"hex":			encodingStep(EncodingHex),
+	"unhex":		decodingStep(EncodingHex),
+	"base32":		encodingStep(EncodingBase32),
+	"unbase32":		decodingStep(EncodingBase32),
+}
+
+// Recipes are the built in named pipelines, usable by name wherever a pipeline is expected.
+var Recipes = map[string][]string{
+	"seal":		{"encode", "xor", "base64"},	// compress the art, encrypt it and make it printable
+	"unseal":	{"unbase64", "xor", "decode"},
+	"scramble":	{"encode", "rot13"},
+	"unscramble":	{"rot13", "decode"},
+}
+
+// Pipeline is an ordered list of steps, optionally with a recipe name.
+type Pipeline struct {
+	Name	string
+	Steps	[]string
+}
+
+// PipelineStepNames returns every available step name in alphabetical order.
+func PipelineStepNames() []string {
+	names := make([]string, 0, len(pipelineSteps))
+	for name := range pipelineSteps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RecipeNames returns the built in recipe names in alphabetical order.
+func RecipeNames() []string {
+	names := make([]string, 0, len(Recipes))
+	for name := range Recipes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+	ParsePipeline builds a pipeline from its text form:
+	- a recipe name, e.g. "seal".
+	- a list of steps separated by ',' or '>', e.g. "encode,xor,base64".
+	- a named list of steps, e.g. "myrecipe=encode>rot13".
+	every step name is validated.
+*/
+func ParsePipeline(spec string) (Pipeline, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return Pipeline{}, errors.New("pipeline cannot be empty")
+	}
+
+	if steps, ok := Recipes[spec]; ok {
+		return Pipeline{Name: spec, Steps: append([]string(nil), steps...)}, nil
+	}
+
+	var pipeline Pipeline
+	if name, list, found := strings.Cut(spec, "="); found {
+		pipeline.Name = strings.TrimSpace(name)
+		spec = list
+	}
+
+	for _, step := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '>' }) {
+		step = strings.ToLower(strings.TrimSpace(step))
+		if step == "" {
+			continue
+		}
+		if _, ok := pipelineSteps[step]; !ok {
+			// transforms carry their arguments in the step, e.g. "scale:2".
+			t, err := ParseTransform(step)
+			if err != nil && isTransformName(step) {
+				return Pipeline{}, fmt.Errorf("step %q: %w", step, err)
+			}
+			if err != nil {
+				return Pipeline{}, fmt.Errorf("unknown pipeline step %q, expected one of: %s or a transform (%s)",
+					step, strings.Join(PipelineStepNames(), ", "), strings.Join(TransformNames, ", "))
+			}
+			step = t.String()
+		}
+		pipeline.Steps = append(pipeline.Steps, step)
+	}
+	if len(pipeline.Steps) == 0 {
+		return Pipeline{}, errors.New("pipeline has no steps")
+	}
+	return pipeline, nil
+}
+
+// String returns the steps joined with arrows, prefixed with the recipe name if there is one.
+func (p Pipeline) String() string {
+	chain := strings.Join(p.Steps, " → ")
+	if p.Name != "" {
+		return p.Name + ": " + chain
+	}
+	return chain
+}
+
+// Run passes the input through every step in order and returns the final result.
+func (p Pipeline) Run(input string, opts PipelineOptions) (string, error) {
+	for i, name := range p.Steps {
+		step, ok := lookupStep(name)
+		if !ok {
+			return "", fmt.Errorf("step %d: unknown pipeline step %q", i+1, name)
+		}
+		if opts.BeforeStep != nil {
+			if err := opts.BeforeStep(name, input); err != nil {
+				return "", fmt.Errorf("step %d (%s): %w", i+1, name, err)
+			}
+		}
+		output, err := step.run(input, opts)
+		if err != nil {
+			return "", fmt.Errorf("step %d (%s): %w", i+1, name, err)
+		}
+		input = output
+	}
+	return input, nil
+}
+
+// Inverse returns the pipeline undoing p: inverse steps in reverse order.
+// Returns an error when a step (like upper or lower) can't be undone.
+func (p Pipeline) Inverse() (Pipeline, error) {
+	inverse := Pipeline{Steps: make([]string, 0, len(p.Steps))}
+	if p.Name != "" {
+		inverse.Name = "inverse " + p.Name
+	}
+	for i := len(p.Steps) - 1; i >= 0; i-- {
+		step, _ := lookupStep(p.Steps[i])
+		if step.inverse == "" {
+			return Pipeline{}, fmt.Errorf("step %q can't be inverted", p.Steps[i])
+		}
+		inverse.Steps = append(inverse.Steps, step.inverse)
+	}
+	return inverse, nil
+}
+
+// lookupStep returns the named step, or a transform step for transform specs like "rotate:90".
+func lookupStep(name string) (pipelineStep, bool) {
+	if step, ok := pipelineSteps[name]; ok {
+		return step, true
+	}
+	t, err := ParseTransform(name)
+	if err != nil {
+		return pipelineStep{}, false
+	}
+	return transformStep(t), true
+}
+
+// transformStep wraps a transform as a step, mirror, flip and rotate can be undone.
+func transformStep(t Transform) pipelineStep {
+	step := pipelineStep{
+		run: func(input string, _ PipelineOptions) (string, error) { return t.Apply(input) },
+	}
+	switch t.Name {
+	case TransformMirror, TransformFlip:
+		step.inverse = t.String()
+	case TransformRotate:
+		step.inverse = Transform{Name: TransformRotate, Degrees: 360 - t.Degrees}.String()
+	}
+	return step
+}
+
+// encodeStep compresses art into the bracket format.
+func encodeStep(input string, opts PipelineOptions) (string, error) {
+	return Encode(input, opts.MultiLine)
+}
+
+// decodeStep expands the bracket format back to art.
+func decodeStep(input string, opts PipelineOptions) (string, error) {
+	return Decode(input, opts.MultiLine)
+}
+
+// xorStep xors the raw bytes, add an encoding step after it to get printable text.
+func xorStep(input string, opts PipelineOptions) (string, error) {
+	if len(opts.Key) == 0 {
+		return "", errors.New("key cannot be empty")
+	}
+	return string(XorBytes([]byte(input), opts.Key)), nil
+}
+
+// encodingStep returns a step converting bytes to text in the given encoding.
+func encodingStep(encoding string) pipelineStep {
+	return pipelineStep{
+		run: func(input string, _ PipelineOptions) (string, error) {
+			return EncodeBytes([]byte(input), encoding)
+		},
+		inverse: "un" + encoding,
+	}
+}
+
+// decodingStep returns a step converting text in the given encoding back to bytes.
+func decodingStep(encoding string) pipelineStep {
+	return pipelineStep{
+		run: func(input string, _ PipelineOptions) (string, error) {
+			data, err := DecodeBytes(input, encoding)
+			return string(data), err
+		},
+		inverse: encoding,
+	}
+}
+
+// reverseRunes reverses the characters of the input, binary input is reversed byte by byte.
+func reverseRunes(input string) string {
+	if !utf8.ValidString(input) {
+		data := []byte(input)
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+		return string(data)
+	}
+	runes := []rune(input)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
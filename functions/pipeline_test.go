@@ -0,0 +1,142 @@
+package functions
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		spec	string
+		name	string
+		steps	[]string
+		wantErr	string
+	}{
+		{"seal", "seal", []string{"encode", "xor", "base64"}, ""},
+		{"encode,xor,base64", "", []string{"encode", "xor", "base64"}, ""},
+		{" Encode > ROT13 ", "", []string{"encode", "rot13"}, ""},
+		{"mine=encode>hex", "mine", []string{"encode", "hex"}, ""},
+		{"encode,,hex,", "", []string{"encode", "hex"}, ""},
+		{"rotate:90,scale:2", "", []string{"rotate:90", "scale:2"}, ""},
+		{"", "", nil, "cannot be empty"},
+		{",,", "", nil, "no steps"},
+		{"encode,explode", "", nil, `unknown pipeline step "explode"`},
+		{"rotate:45", "", nil, `step "rotate:45"`},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			pipeline, err := ParsePipeline(test.spec)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("ParsePipeline error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pipeline.Name != test.name || !slices.Equal(pipeline.Steps, test.steps) {
+				t.Errorf("ParsePipeline = %q %v, want %q %v", pipeline.Name, pipeline.Steps, test.name, test.steps)
+			}
+		})
+	}
+}
+
+func TestPipelineInverse(t *testing.T) {
+	tests := []struct {
+		spec	string
+		steps	[]string
+		wantErr	bool
+	}{
+		{"seal", []string{"unbase64", "xor", "decode"}, false},
+		{"encode,rot13,hex", []string{"unhex", "rot13", "decode"}, false},
+		{"mirror,rotate:90,flip", []string{"flip", "rotate:270", "mirror"}, false},
+		{"reverse,unbase32", []string{"base32", "reverse"}, false},
+		{"encode,upper", nil, true},
+		{"scale:2", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			pipeline, err := ParsePipeline(test.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inverse, err := pipeline.Inverse()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Inverse error = %v, want error %v", err, test.wantErr)
+			}
+			if !slices.Equal(inverse.Steps, test.steps) {
+				t.Errorf("Inverse = %v, want %v", inverse.Steps, test.steps)
+			}
+		})
+	}
+}
+
+// every recipe followed by its inverse gives back the art.
+func TestPipelineRoundTrip(t *testing.T) {
+	art := "  /\\\n /##\\\n/####\\\n══════"
+	specs := append(RecipeNames(), "encode,reverse,base32", "mirror,rotate:90,encode,xor,hex", "encode,rot13,base64url")
+	for _, spec := range specs {
+		t.Run(spec, func(t *testing.T) {
+			pipeline, err := ParsePipeline(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := PipelineOptions{Key: []byte("key"), MultiLine: true}
+			// recipes undoing others (unseal, unscramble) start from their packed form.
+			input := art
+			if inverse, ok := recipeInverses[spec]; ok {
+				packer, _ := ParsePipeline(inverse)
+				if input, err = packer.Run(art, opts); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			packed, err := pipeline.Run(input, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inverse, err := pipeline.Inverse()
+			if err != nil {
+				t.Fatal(err)
+			}
+			unpacked, err := inverse.Run(packed, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if unpacked != input {
+				t.Errorf("%s then its inverse gave %q, want %q", spec, unpacked, input)
+			}
+		})
+	}
+}
+
+// recipeInverses maps the recipes undoing another one to the recipe they undo.
+var recipeInverses = map[string]string{"unseal": "seal", "unscramble": "scramble"}
+
+func TestPipelineRunErrors(t *testing.T) {
+	stop := errors.New("stopped")
+	tests := []struct {
+		spec	string
+		input	string
+		opts	PipelineOptions
+		want	string
+	}{
+		{"xor", "art", PipelineOptions{}, "step 1 (xor): key cannot be empty"},
+		{"encode,unhex", "art", PipelineOptions{}, "step 2 (unhex): input is not valid hex"},
+		{"decode", "[3 a", PipelineOptions{}, "step 1 (decode): line 1"},
+		{"encode", "art", PipelineOptions{BeforeStep: func(step, input string) error { return stop }}, "step 1 (encode): stopped"},
+	}
+	for _, test := range tests {
+		pipeline, err := ParsePipeline(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = pipeline.Run(test.input, test.opts)
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%s: error = %v, want %q", test.spec, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// size of one character cell in font pixels: the glyph plus spacing.
+const (
+	cellWidth	= glyphWidth + 1
+	cellHeight	= glyphHeight + 2
+)
+
+// limits of rendered images, checked before the image is allocated.
+const (
+	MaxRenderColumns	= 1000
+	MaxRenderRows		= 1000
+	MaxRenderPixels		= 16 << 20 // one byte per pixel, so at most 16 MB per image
+	MaxAnimationPixels	= 64 << 20 // all frames of a GIF together
+)
+
+// ErrImageTooLarge is returned for art that would render to an image beyond the limits.
+var ErrImageTooLarge = errors.New("image is too large, the art can have at most 1000 columns and 1000 rows and the image 16 megapixels")
+
+// RenderOptions controls how art is drawn to an image.
+type RenderOptions struct {
+	Foreground	color.RGBA
+	Background	color.RGBA
+	Padding		int // empty space around the art, in pixels
+	Scale		int // size of one font pixel in image pixels (PNG only)
+	FontSize	int // font size in pixels (SVG only)
+}
+
+// DefaultRenderOptions returns light text on the dark background used by the web interface.
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{
+		Foreground:	color.RGBA{0xf1, 0xf5, 0xf9, 0xff},
+		Background:	color.RGBA{0x12, 0x12, 0x12, 0xff},
+		Padding:	16,
+		Scale:		2,
+		FontSize:	14,
+	}
+}
+
+// ParseColor parses a "#rrggbb" or "#rgb" colour, the '#' is optional.
+func ParseColor(s string) (color.RGBA, error) {
+	hexColor := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hexColor) == 3 {
+		hexColor = string([]byte{hexColor[0], hexColor[0], hexColor[1], hexColor[1], hexColor[2], hexColor[2]})
+	}
+	value, err := strconv.ParseUint(hexColor, 16, 32)
+	if len(hexColor) != 6 || err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid colour %q, expected #rrggbb or #rgb", s)
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}, nil
+}
+
+// artLines splits art into lines of characters, ignoring the final newline and colour escape sequences.
+func artLines(art string) [][]rune {
+	lines := strings.Split(strings.TrimSuffix(StripANSI(art), "\n"), "\n")
+	result := make([][]rune, len(lines))
+	for i, line := range lines {
+		result[i] = []rune(line)
+	}
+	return result
+}
+
+// artSize returns the width of the longest line and the number of lines.
+func artSize(lines [][]rune) (int, int) {
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+	return width, len(lines)
+}
+
+// ArtDimensions returns the width of the widest line and the number of lines of the art.
+func ArtDimensions(art string) (width, height int) {
+	return artSize(artLines(art))
+}
+
+// imageSize returns the size in pixels of an image of columns × rows characters,
+// ErrImageTooLarge when it is beyond the limits.
+func imageSize(columns, rows int, opts RenderOptions) (width, height int, err error) {
+	if columns > MaxRenderColumns || rows > MaxRenderRows {
+		return 0, 0, ErrImageTooLarge
+	}
+	scale := max(opts.Scale, 1)
+	padding := max(opts.Padding, 0)
+	// floats can't overflow for huge scales or paddings.
+	w := float64(columns)*cellWidth*float64(scale) + 2*float64(padding)
+	h := float64(rows)*cellHeight*float64(scale) + 2*float64(padding)
+	if w*h > MaxRenderPixels {
+		return 0, 0, ErrImageTooLarge
+	}
+	return int(w), int(h), nil
+}
+
+// RenderImage draws the art with the built in bitmap font.
+// The image uses a two colour palette so it can also be used as a GIF frame.
+// art beyond the limits is rejected with ErrImageTooLarge before anything is allocated.
+func RenderImage(art string, opts RenderOptions) (*image.Paletted, error) {
+	lines := artLines(art)
+	columns, rows := artSize(lines)
+	width, height, err := imageSize(columns, rows, opts)
+	if err != nil {
+		return nil, err
+	}
+	scale := max(opts.Scale, 1)
+	padding := max(opts.Padding, 0)
+	img := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{opts.Background, opts.Foreground})
+
+	for row, line := range lines {
+		for column, r := range line {
+			if r == ' ' {
+				continue
+			}
+			glyph := glyphFor(r)
+			left := padding + column*cellWidth*scale
+			top := padding + row*cellHeight*scale + scale // one pixel of line spacing above the glyph
+			for gx := 0; gx < glyphWidth; gx++ {
+				for gy := 0; gy < glyphHeight; gy++ {
+					if !glyphPixel(glyph, gx, gy) {
+						continue
+					}
+					for dx := 0; dx < scale; dx++ {
+						for dy := 0; dy < scale; dy++ {
+							img.SetColorIndex(left+gx*scale+dx, top+gy*scale+dy, 1)
+						}
+					}
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// RenderPNG writes the art as a PNG image.
+func RenderPNG(w io.Writer, art string, opts RenderOptions) error {
+	img, err := RenderImage(art, opts)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("error encoding PNG: %w", err)
+	}
+	return nil
+}
+
+/*
+	RenderSVG writes the art as an SVG image with one <tspan> per row.
+	the text uses the viewer's monospace font, so the size is an estimate of 0.6em per character.
+	the columns and rows have the same limits as the other images.
+*/
+func RenderSVG(w io.Writer, art string, opts RenderOptions) error {
+	lines := artLines(art)
+	columns, rows := artSize(lines)
+	if columns > MaxRenderColumns || rows > MaxRenderRows {
+		return ErrImageTooLarge
+	}
+	fontSize := max(opts.FontSize, 1)
+	lineHeight := fontSize * 6 / 5
+	padding := max(opts.Padding, 0)
+
+	width := columns*fontSize*3/5 + 2*padding
+	height := rows*lineHeight + 2*padding
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", hexColor(opts.Background))
+	fmt.Fprintf(&b, "<text font-family=\"monospace\" font-size=\"%d\" fill=\"%s\" xml:space=\"preserve\" style=\"white-space:pre\">\n", fontSize, hexColor(opts.Foreground))
+	for row, line := range lines {
+		// baseline sits at about 80% of the line height.
+		y := padding + row*lineHeight + lineHeight*4/5
+		fmt.Fprintf(&b, "<tspan x=\"%d\" y=\"%d\">%s</tspan>\n", padding, y, html.EscapeString(string(line)))
+	}
+	b.WriteString("</text>\n</svg>\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("error writing SVG: %w", err)
+	}
+	return nil
+}
+
+// hexColor formats the colour as "#rrggbb".
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,55 @@
+package functions
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRenderLimits(t *testing.T) {
+	defaults := DefaultRenderOptions()
+	huge := defaults
+	huge.Scale, huge.Padding = math.MaxInt, math.MaxInt
+
+	tests := []struct {
+		name	string
+		art		string
+		opts	RenderOptions
+		wantErr	bool
+	}{
+		{"small", "#-#\n-#-", defaults, false},
+		{"empty", "", defaults, false},
+		{"columns limit", strings.Repeat("#", MaxRenderColumns), defaults, false},
+		{"too many columns", strings.Repeat("#", MaxRenderColumns+1), defaults, true},
+		{"too many rows", strings.Repeat("#\n", MaxRenderRows+1), defaults, true},
+		{"long line and many empty lines", strings.Repeat("#", 1000) + strings.Repeat("\n", 999), defaults, true},
+		{"huge scale and padding", "#", huge, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := RenderPNG(&buf, test.art, test.opts)
+			if test.wantErr != errors.Is(err, ErrImageTooLarge) {
+				t.Errorf("RenderPNG: error %v, want ErrImageTooLarge: %v", err, test.wantErr)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("RenderPNG: %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderImageSize(t *testing.T) {
+	opts := DefaultRenderOptions()
+	img, err := RenderImage("##\n#", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWidth := 2*cellWidth*opts.Scale + 2*opts.Padding
+	wantHeight := 2*cellHeight*opts.Scale + 2*opts.Padding
+	if size := img.Bounds().Size(); size.X != wantWidth || size.Y != wantHeight {
+		t.Errorf("image is %v, want %dx%d", size, wantWidth, wantHeight)
+	}
+}
@@ -0,0 +1,124 @@
+package functions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//byteOrderMark is the UTF-8 byte order mark some editors put at the start of text files.
+const byteOrderMark = "\uFEFF"
+
+//TextFormat is the layout of a text file, kept when the processed text is written back.
+type TextFormat struct {
+	CRLF			bool //lines end with "\r\n" instead of "\n"
+	FinalNewline	bool //the last line ends with a line ending
+	BOM				bool //the file starts with a byte order mark
+}
+
+//NormalizedFormat is the layout used with --normalize: "\n" line endings, a final newline and no byte order mark.
+var NormalizedFormat = TextFormat{FinalNewline: true}
+
+/*DetectTextFormat returns the layout of the file content.
+files mixing both line endings count as CRLF when most of their lines end with "\r\n".*/
+func DetectTextFormat(data string) TextFormat {
+	format := TextFormat{BOM: strings.HasPrefix(data, byteOrderMark)}
+	data = strings.TrimPrefix(data, byteOrderMark)
+	crlf := strings.Count(data, "\r\n")
+	format.CRLF = crlf > strings.Count(data, "\n")-crlf
+	format.FinalNewline = strings.HasSuffix(data, "\n")
+	return format
+}
+
+//NormalizeText removes the byte order mark and the final newline and turns "\r\n" into "\n".
+func NormalizeText(data string) string {
+	data = strings.TrimPrefix(data, byteOrderMark)
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	return strings.TrimSuffix(data, "\n")
+}
+
+//Apply lays out text, which uses "\n" and has no final newline, in the format.
+func (f TextFormat) Apply(text string) string {
+	if f.FinalNewline {
+		text += "\n"
+	}
+	if f.CRLF {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	if f.BOM {
+		text = byteOrderMark + text
+	}
+	return text
+}
+
+//String describes the format like "CRLF, final newline, BOM".
+func (f TextFormat) String() string {
+	parts := []string{"LF"}
+	if f.CRLF {
+		parts[0] = "CRLF"
+	}
+	if f.FinalNewline {
+		parts = append(parts, "final newline")
+	} else {
+		parts = append(parts, "no final newline")
+	}
+	if f.BOM {
+		parts = append(parts, "BOM")
+	}
+	return strings.Join(parts, ", ")
+}
+
+//ReadTextFile reads the whole file ("-" for standard input) and returns the normalized text with the format of the file.
+//lines longer than MaxLineSize are an error.
+func ReadTextFile(filePath string) (string, TextFormat, error) {
+	if filePath == Stdio {
+		return readTextFormat(os.Stdin)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", TextFormat{}, fmt.Errorf("error reading file: %w", err)
+	}
+	defer file.Close()
+
+	return readTextFormat(file)
+}
+
+/*readTextFormat reads the text line by line, detecting the format as it goes,
+so the line size limit is enforced before a long line is held in memory.*/
+func readTextFormat(r io.Reader) (string, TextFormat, error) {
+	reader := bufio.NewReader(r)
+	var format TextFormat
+	if bom, _ := reader.Peek(len(byteOrderMark)); string(bom) == byteOrderMark {
+		reader.Discard(len(bom))
+		format.BOM = true
+	}
+
+	var builder strings.Builder
+	crlf, lf := 0, 0
+	for number := 1; ; number++ {
+		line, ending, err := readTextLine(reader, number)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", TextFormat{}, err
+		}
+		if number > 1 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(line)
+
+		switch ending {
+		case "\r\n":
+			crlf++
+		case "\n":
+			lf++
+		}
+		format.FinalNewline = ending != ""
+	}
+	//files mixing both line endings count as CRLF when most of their lines end with "\r\n", as in DetectTextFormat.
+	format.CRLF = crlf > lf
+	return builder.String(), format, nil
+}
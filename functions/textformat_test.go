@@ -0,0 +1,98 @@
+package functions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var textFormatTests = []struct {
+	name	string
+	data	string
+	text	string
+	format	TextFormat
+	written	string // what Apply writes back when it differs from data
+}{
+	{"empty", "", "", TextFormat{}, ""},
+	{"lf", "a\nb\n", "a\nb", TextFormat{FinalNewline: true}, ""},
+	{"no final newline", "a\nb", "a\nb", TextFormat{}, ""},
+	{"crlf", "a\r\nb\r\n", "a\nb", TextFormat{CRLF: true, FinalNewline: true}, ""},
+	{"mostly crlf", "a\r\nb\r\nc\n", "a\nb\nc", TextFormat{CRLF: true, FinalNewline: true}, "a\r\nb\r\nc\r\n"},
+	{"bom", byteOrderMark + "a\n", "a", TextFormat{FinalNewline: true, BOM: true}, ""},
+	{"blank last line", "a\n\n", "a\n", TextFormat{FinalNewline: true}, ""},
+	{"lone carriage return", "a\rb\n", "a\rb", TextFormat{FinalNewline: true}, ""},
+}
+
+func TestTextFormatRoundTrip(t *testing.T) {
+	for _, test := range textFormatTests {
+		t.Run(test.name, func(t *testing.T) {
+			format := DetectTextFormat(test.data)
+			text := NormalizeText(test.data)
+			if format != test.format || text != test.text {
+				t.Errorf("got %q (%v), want %q (%v)", text, format, test.text, test.format)
+			}
+			want := test.data
+			if test.written != "" {
+				want = test.written
+			}
+			if got := format.Apply(text); got != want {
+				t.Errorf("Apply = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// reading a file line by line must find the same text and format as the whole file.
+func TestReadTextFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range textFormatTests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			if err := os.WriteFile(path, []byte(test.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			text, format, err := ReadTextFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != test.format || text != test.text {
+				t.Errorf("got %q (%v), want %q (%v)", text, format, test.text, test.format)
+			}
+		})
+	}
+}
+
+func TestReadTextFileLineLimit(t *testing.T) {
+	defer func(size int) { MaxLineSize = size }(MaxLineSize)
+	MaxLineSize = 4
+
+	tests := []struct {
+		name	string
+		data	string
+		line	int // the line reported as too long, 0 for none
+	}{
+		{"within the limit", "abcd\r\nabcd\n", 0},
+		{"bom isn't counted", byteOrderMark + "abcd\n", 0},
+		{"long line", "abcd\nabcde\nabc\n", 2},
+		{"long last line", "abc\n" + strings.Repeat("a", 100000), 2},
+	}
+	dir := t.TempDir()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name)
+			if err := os.WriteFile(path, []byte(test.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err := ReadTextFile(path)
+			var tooLong *LineTooLongError
+			switch {
+			case test.line == 0 && err != nil:
+				t.Errorf("ReadTextFile: %v", err)
+			case test.line != 0 && (!errors.As(err, &tooLong) || tooLong.Line != test.line):
+				t.Errorf("ReadTextFile error = %v, want line %d too long", err, test.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxScale is the largest factor accepted by the scale transform.
+const MaxScale = 8
+
+// MaxPadWidth is the largest width accepted by the pad transform.
+const MaxPadWidth = 1000
+
+// ErrTransformTooLarge is returned by CheckTransforms for transforms growing the art beyond the limit.
+var ErrTransformTooLarge = errors.New("the transformed art would be too large")
+
+// transform names, arguments follow the name separated by ':' (e.g. "scale:2").
+const (
+	TransformMirror	= "mirror"
+	TransformFlip	= "flip"
+	TransformRotate	= "rotate"
+	TransformScale	= "scale"
+	TransformCrop	= "crop"
+	TransformPad	= "pad"
+)
+
+// TransformNames lists the transforms with the form of their arguments, for usage texts.
+var TransformNames = []string{"mirror", "flip", "rotate[:90|180|270]", "scale:N", "crop:WxH[+X+Y]", "pad:W[:left|center|right]"}
+
+// characters replaced by their mirror image when the art is mirrored horizontally.
+var mirrorChars = pairMap("/\\", "()", "<>", "[]", "{}", "«»", "╱╲", "┌┐", "└┘", "├┤", "╭╮", "╰╯", "╔╗", "╚╝", "▌▐")
+
+// characters replaced when the art is flipped upside down.
+var flipChars = pairMap("/\\", "_‾", "^v", "┌└", "┐┘", "┬┴", "╭╰", "╮╯", "╔╚", "╗╝", "▀▄", "╱╲")
+
+// characters replaced when the art is rotated 90° clockwise, the map is followed once per quarter turn.
+// every replacement is reversible so rotating all the way around gives back the original art.
+var rotateChars = map[rune]rune{
+	'-': '|', '|': '-', '/': '\\', '\\': '/',
+	'^': '>', '>': 'v', 'v': '<', '<': '^',
+	'─': '│', '│': '─', '═': '║', '║': '═',
+}
+
+// pairMap returns a map swapping the two characters of every pair.
+func pairMap(pairs ...string) map[rune]rune {
+	m := make(map[rune]rune, 2*len(pairs))
+	for _, pair := range pairs {
+		r := []rune(pair)
+		m[r[0]], m[r[1]] = r[1], r[0]
+	}
+	return m
+}
+
+// Transform is a geometric operation on decoded art with its arguments.
+type Transform struct {
+	Name	string
+	Degrees	int		// rotate: 90, 180 or 270 clockwise
+	Factor	int		// scale
+	X, Y	int		// crop: top left corner
+	Width	int		// crop and pad
+	Height	int		// crop
+	Align	string	// pad: left, center or right
+}
+
+/*
+	ParseTransform parses a single transform:
+	- "mirror" and "flip" take no arguments.
+	- "rotate" turns 90° clockwise, "rotate:180" and "rotate:270" turn further.
+	- "scale:N" repeats every character N times in both directions.
+	- "crop:WxH+X+Y" keeps W columns and H rows starting at column X, row Y (the offset is optional).
+	- "pad:W" pads every line to W columns (at most MaxPadWidth), optionally aligned with ":center" or ":right".
+*/
+func ParseTransform(spec string) (Transform, error) {
+	name, args, _ := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+	t := Transform{Name: name}
+
+	switch name {
+	case TransformMirror, TransformFlip:
+		if args != "" {
+			return Transform{}, fmt.Errorf("%s takes no arguments", name)
+		}
+	case TransformRotate:
+		t.Degrees = 90
+		if args != "" {
+			degrees, err := strconv.Atoi(args)
+			if err != nil || (degrees != 90 && degrees != 180 && degrees != 270) {
+				return Transform{}, fmt.Errorf("invalid rotation %q, expected 90, 180 or 270", args)
+			}
+			t.Degrees = degrees
+		}
+	case TransformScale:
+		factor, err := strconv.Atoi(args)
+		if err != nil || factor < 1 || factor > MaxScale {
+			return Transform{}, fmt.Errorf("invalid scale %q, expected a factor from 1 to %d", args, MaxScale)
+		}
+		t.Factor = factor
+	case TransformCrop:
+		if err := parseGeometry(args, &t); err != nil {
+			return Transform{}, err
+		}
+	case TransformPad:
+		width, align, _ := strings.Cut(args, ":")
+		w, err := strconv.Atoi(width)
+		if err != nil || w < 1 || w > MaxPadWidth {
+			return Transform{}, fmt.Errorf("invalid pad width %q, expected 1 to %d", width, MaxPadWidth)
+		}
+		t.Width = w
+		t.Align = "left"
+		if align != "" {
+			if align != "left" && align != "center" && align != "right" {
+				return Transform{}, fmt.Errorf("invalid alignment %q, expected left, center or right", align)
+			}
+			t.Align = align
+		}
+	default:
+		return Transform{}, fmt.Errorf("unknown transform %q, expected one of: %s", name, strings.Join(TransformNames, ", "))
+	}
+	return t, nil
+}
+
+// isTransformName reports whether spec starts with a transform name, whatever its arguments.
+func isTransformName(spec string) bool {
+	name, _, _ := strings.Cut(spec, ":")
+	switch name {
+	case TransformMirror, TransformFlip, TransformRotate, TransformScale, TransformCrop, TransformPad:
+		return true
+	}
+	return false
+}
+
+// ParseTransforms parses a comma separated list of transforms, applied in order.
+func ParseTransforms(list string) ([]Transform, error) {
+	var transforms []Transform
+	for _, spec := range strings.Split(list, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		t, err := ParseTransform(spec)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(transforms, t)
+	}
+	return transforms, nil
+}
+
+// parseGeometry parses the "WxH+X+Y" argument of crop into t.
+func parseGeometry(args string, t *Transform) error {
+	invalid := fmt.Errorf("invalid crop %q, expected WxH or WxH+X+Y", args)
+	size, offset, hasOffset := strings.Cut(args, "+")
+	width, height, found := strings.Cut(size, "x")
+	if !found {
+		return invalid
+	}
+	values := []string{width, height, "0", "0"}
+	if hasOffset {
+		x, y, found := strings.Cut(offset, "+")
+		if !found {
+			return invalid
+		}
+		values[2], values[3] = x, y
+	}
+	numbers := make([]int, len(values))
+	for i, value := range values {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return invalid
+		}
+		numbers[i] = n
+	}
+	if numbers[0] == 0 || numbers[1] == 0 {
+		return invalid
+	}
+	t.Width, t.Height, t.X, t.Y = numbers[0], numbers[1], numbers[2], numbers[3]
+	return nil
+}
+
+// String returns the transform in the form accepted by ParseTransform.
+func (t Transform) String() string {
+	switch t.Name {
+	case TransformRotate:
+		return fmt.Sprintf("rotate:%d", t.Degrees)
+	case TransformScale:
+		return fmt.Sprintf("scale:%d", t.Factor)
+	case TransformCrop:
+		return fmt.Sprintf("crop:%dx%d+%d+%d", t.Width, t.Height, t.X, t.Y)
+	case TransformPad:
+		return fmt.Sprintf("pad:%d:%s", t.Width, t.Align)
+	default:
+		return t.Name
+	}
+}
+
+/*
+	Apply runs the transform on decoded art.
+	- lines are handled as grids of characters, shorter lines are treated as padded with spaces.
+	- trailing spaces are trimmed from the result, except for pad which adds them on purpose.
+	- a final newline in the input is kept.
+	coloured art is rejected, the escape sequences can't be moved around with the characters.
+*/
+func (t Transform) Apply(art string) (string, error) {
+	if HasANSI(art) {
+		return "", errors.New("transforms don't support coloured art, strip the colours first")
+	}
+	lines := artLines(art)
+
+	var result [][]rune
+	switch t.Name {
+	case TransformMirror:
+		result = mirrorLines(lines)
+	case TransformFlip:
+		result = flipLines(lines)
+	case TransformRotate:
+		result = lines
+		for i := 0; i < t.Degrees/90; i++ {
+			result = rotateLines(result)
+		}
+	case TransformScale:
+		result = scaleLines(lines, t.Factor)
+	case TransformCrop:
+		var err error
+		if result, err = cropLines(lines, t); err != nil {
+			return "", err
+		}
+	case TransformPad:
+		var err error
+		if result, err = padLines(lines, t.Width, t.Align); err != nil {
+			return "", err
+		}
+		return joinLines(result, false, strings.HasSuffix(art, "\n")), nil
+	default:
+		return "", fmt.Errorf("unknown transform %q", t.Name)
+	}
+	return joinLines(result, true, strings.HasSuffix(art, "\n")), nil
+}
+
+// ApplyTransforms runs every transform in order.
+func ApplyTransforms(art string, transforms []Transform) (string, error) {
+	for _, t := range transforms {
+		var err error
+		if art, err = t.Apply(art); err != nil {
+			return "", fmt.Errorf("%s: %w", t.Name, err)
+		}
+	}
+	return art, nil
+}
+
+// ResultSize returns the columns and rows of the grid the transform turns a columns × rows grid into.
+func (t Transform) ResultSize(columns, rows int) (int, int) {
+	switch t.Name {
+	case TransformRotate:
+		if t.Degrees != 180 {
+			return rows, columns
+		}
+	case TransformScale:
+		return columns * t.Factor, rows * t.Factor
+	case TransformCrop:
+		return min(t.Width, max(columns-t.X, 0)), min(t.Height, max(rows-t.Y, 0))
+	case TransformPad:
+		return max(t.Width, columns), rows
+	}
+	return columns, rows
+}
+
+/*
+	CheckTransforms projects the size of the art through every transform without running them,
+	ErrTransformTooLarge is returned when any intermediate result would have more than maxCells characters,
+	counting one line ending per row. it is meant for limits checked before anything is allocated.
+*/
+func CheckTransforms(art string, transforms []Transform, maxCells int) error {
+	columns, rows := ArtDimensions(art)
+	for _, t := range transforms {
+		columns, rows = t.ResultSize(columns, rows)
+		if (columns+1)*rows > maxCells {
+			return fmt.Errorf("%s: %w", t, ErrTransformTooLarge)
+		}
+	}
+	return nil
+}
+
+// cellAt returns the character at row y, column x, a space outside of the line.
+func cellAt(lines [][]rune, x, y int) rune {
+	if y >= 0 && y < len(lines) && x >= 0 && x < len(lines[y]) {
+		return lines[y][x]
+	}
+	return ' '
+}
+
+// remap returns the replacement for r from chars, or r itself.
+func remap(chars map[rune]rune, r rune) rune {
+	if replacement, ok := chars[r]; ok {
+		return replacement
+	}
+	return r
+}
+
+// mirrorLines reverses every line within the width of the art.
+func mirrorLines(lines [][]rune) [][]rune {
+	width, height := artSize(lines)
+	result := make([][]rune, height)
+	for y := range result {
+		result[y] = make([]rune, width)
+		for x := range width {
+			result[y][width-1-x] = remap(mirrorChars, cellAt(lines, x, y))
+		}
+	}
+	return result
+}
+
+// flipLines turns the art upside down.
+func flipLines(lines [][]rune) [][]rune {
+	result := make([][]rune, len(lines))
+	for y, line := range lines {
+		flipped := make([]rune, len(line))
+		for x, r := range line {
+			flipped[x] = remap(flipChars, r)
+		}
+		result[len(lines)-1-y] = flipped
+	}
+	return result
+}
+
+// rotateLines turns the art 90° clockwise: the bottom row becomes the first column.
+func rotateLines(lines [][]rune) [][]rune {
+	width, height := artSize(lines)
+	result := make([][]rune, width)
+	for y := range result {
+		result[y] = make([]rune, height)
+		for x := range height {
+			result[y][x] = remap(rotateChars, cellAt(lines, y, height-1-x))
+		}
+	}
+	return result
+}
+
+// scaleLines repeats every character factor times horizontally and every line factor times vertically.
+func scaleLines(lines [][]rune, factor int) [][]rune {
+	result := make([][]rune, 0, len(lines)*factor)
+	for _, line := range lines {
+		scaled := make([]rune, 0, len(line)*factor)
+		for _, r := range line {
+			for range factor {
+				scaled = append(scaled, r)
+			}
+		}
+		for range factor {
+			result = append(result, scaled)
+		}
+	}
+	return result
+}
+
+// cropLines keeps the region of t.Width columns and t.Height rows at t.X, t.Y.
+func cropLines(lines [][]rune, t Transform) ([][]rune, error) {
+	width, height := artSize(lines)
+	if t.X >= width || t.Y >= height {
+		return nil, fmt.Errorf("crop offset %d+%d is outside of the %dx%d art", t.X, t.Y, width, height)
+	}
+	result := make([][]rune, 0, t.Height)
+	for y := t.Y; y < min(t.Y+t.Height, height); y++ {
+		row := make([]rune, 0, t.Width)
+		for x := t.X; x < min(t.X+t.Width, width); x++ {
+			row = append(row, cellAt(lines, x, y))
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// padLines pads every line with spaces to width columns, aligned as given.
+func padLines(lines [][]rune, width int, align string) ([][]rune, error) {
+	result := make([][]rune, len(lines))
+	for i, line := range lines {
+		missing := width - len(line)
+		if missing < 0 {
+			return nil, fmt.Errorf("line %d is %d characters wide, more than %d", i+1, len(line), width)
+		}
+		left := 0
+		switch align {
+		case "center":
+			left = missing / 2
+		case "right":
+			left = missing
+		}
+		padded := []rune(strings.Repeat(" ", left))
+		padded = append(padded, line...)
+		result[i] = append(padded, []rune(strings.Repeat(" ", missing-left))...)
+	}
+	return result, nil
+}
+
+// joinLines turns the grid back into text, optionally trimming trailing spaces.
+func joinLines(lines [][]rune, trim, finalNewline bool) string {
+	var result strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			result.WriteByte('\n')
+		}
+		text := string(line)
+		if trim {
+			text = strings.TrimRight(text, " ")
+		}
+		result.WriteString(text)
+	}
+	if finalNewline {
+		result.WriteByte('\n')
+	}
+	return result.String()
+}
@@ -0,0 +1,93 @@
+package functions
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTransformResultSize(t *testing.T) {
+	art := "ab\ncdef\ng"
+	for _, spec := range []string{"mirror", "flip", "rotate", "rotate:180", "rotate:270", "scale:3", "crop:2x2+1+0", "crop:9x9", "pad:6", "pad:6:right"} {
+		t.Run(spec, func(t *testing.T) {
+			transform, err := ParseTransform(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := transform.Apply(art)
+			if err != nil {
+				t.Fatal(err)
+			}
+			columns, rows := ArtDimensions(art)
+			wantColumns, wantRows := transform.ResultSize(columns, rows)
+			// trailing spaces are trimmed from the result, so its lines can only be narrower.
+			gotColumns, gotRows := ArtDimensions(result)
+			if gotRows != wantRows || gotColumns > wantColumns {
+				t.Errorf("result is %dx%d, projected %dx%d", gotColumns, gotRows, wantColumns, wantRows)
+			}
+		})
+	}
+}
+
+func TestTransformRoundTrips(t *testing.T) {
+	art := "/--\\\n|  |\n\\--/"
+	tests := []struct {
+		name		string
+		transforms	string
+	}{
+		{"mirror twice", "mirror,mirror"},
+		{"flip twice", "flip,flip"},
+		{"rotate around", "rotate,rotate,rotate,rotate"},
+		{"rotate back", "rotate:90,rotate:270"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transforms, err := ParseTransforms(test.transforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := ApplyTransforms(art, transforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != art {
+				t.Errorf("%s gave\n%s\nwant\n%s", test.transforms, result, art)
+			}
+		})
+	}
+}
+
+func TestParseTransformRejects(t *testing.T) {
+	for _, spec := range []string{"pad:0", "pad:2000000000", "scale:9", "scale:0", "rotate:45", "crop:0x1", "crop:1x1+2", "mirror:1", "spin"} {
+		if _, err := ParseTransform(spec); err == nil {
+			t.Errorf("ParseTransform(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestCheckTransforms(t *testing.T) {
+	art := strings.Repeat(strings.Repeat("#", 99)+"\n", 100)
+	tests := []struct {
+		transforms	string
+		wantErr		bool
+	}{
+		{"mirror,rotate", false},
+		{"scale:2", false},
+		{"scale:8,scale:8,scale:8", true},
+		{"scale:2,crop:10x10,scale:8", false},
+		{"pad:1000", true},
+		{"crop:10x10,pad:1000", false},
+	}
+	for _, test := range tests {
+		t.Run(test.transforms, func(t *testing.T) {
+			transforms, err := ParseTransforms(test.transforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = CheckTransforms(art, transforms, 40000)
+			if got := errors.Is(err, ErrTransformTooLarge); got != test.wantErr {
+				t.Errorf("CheckTransforms: error %v, want ErrTransformTooLarge: %v", err, test.wantErr)
+			}
+		})
+	}
+}
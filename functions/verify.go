@@ -0,0 +1,70 @@
+package functions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mismatch is the first position of a line where two texts differ.
+type Mismatch struct {
+	Line	int    // 1-based
+	Column	int    // 1-based, counted in characters
+	Want	string // expected character, or "end of line" / "end of text"
+	Got		string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("line %d, column %d: expected %s, got %s", m.Line, m.Column, m.Want, m.Got)
+}
+
+/*
+	CompareText compares got with want exactly, unlike DiffArt trailing spaces and missing lines count.
+	returns the first differing column of every line that differs, nil when both texts are identical.
+*/
+func CompareText(want, got string) []Mismatch {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var mismatches []Mismatch
+	for i := range max(len(wantLines), len(gotLines)) {
+		switch {
+		case i >= len(wantLines):
+			mismatches = append(mismatches, Mismatch{Line: i + 1, Column: 1, Want: "end of text", Got: "line " + quoteText(gotLines[i], '"')})
+		case i >= len(gotLines):
+			mismatches = append(mismatches, Mismatch{Line: i + 1, Column: 1, Want: "line " + quoteText(wantLines[i], '"'), Got: "end of text"})
+		default:
+			if mismatch, ok := compareLine(i+1, []rune(wantLines[i]), []rune(gotLines[i])); !ok {
+				mismatches = append(mismatches, mismatch)
+			}
+		}
+	}
+	return mismatches
+}
+
+// compareLine returns the first differing character of the line, ok is true when both are the same.
+func compareLine(line int, want, got []rune) (Mismatch, bool) {
+	for x := range max(len(want), len(got)) {
+		wantChar, gotChar := "end of line", "end of line"
+		if x < len(want) {
+			wantChar = quoteText(string(want[x]), '\'')
+		}
+		if x < len(got) {
+			gotChar = quoteText(string(got[x]), '\'')
+		}
+		if wantChar != gotChar {
+			return Mismatch{Line: line, Column: x + 1, Want: wantChar, Got: gotChar}, false
+		}
+	}
+	return Mismatch{}, true
+}
+
+// VerifyRoundTrip encodes the art and decodes it again, the result must be identical to the art.
+func VerifyRoundTrip(art string) ([]Mismatch, error) {
+	encoded, err := Encode(art, true)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := Decode(encoded, true)
+	if err != nil {
+		return nil, fmt.Errorf("decoding the encoded art: %w", err)
+	}
+	return CompareText(art, decoded), nil
+}
@@ -0,0 +1,53 @@
+package functions
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareText(t *testing.T) {
+	tests := []struct {
+		name		string
+		want, got	string
+		mismatches	[]Mismatch
+	}{
+		{"identical", "ab\ncd", "ab\ncd", nil},
+		{"changed character", "ab\ncd", "ab\nce", []Mismatch{{Line: 2, Column: 2, Want: "'d'", Got: "'e'"}}},
+		{"trailing space", "ab", "ab ", []Mismatch{{Line: 1, Column: 3, Want: "end of line", Got: "' '"}}},
+		{"missing line", "a\nb", "a", []Mismatch{{Line: 2, Column: 1, Want: `line "b"`, Got: "end of text"}}},
+		{"extra line", "a", "a\nb", []Mismatch{{Line: 2, Column: 1, Want: "end of text", Got: `line "b"`}}},
+		{"columns count characters", "══╗", "══╝", []Mismatch{{Line: 1, Column: 3, Want: "'╗'", Got: "'╝'"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CompareText(test.want, test.got); !slices.Equal(got, test.mismatches) {
+				t.Errorf("CompareText = %v, want %v", got, test.mismatches)
+			}
+		})
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	for _, art := range []string{"", "#####", "  /\\\n /##\\\n/####\\", "\x1b[31m##\x1b[0m\n══╗  "} {
+		mismatches, err := VerifyRoundTrip(art)
+		if err != nil {
+			t.Errorf("VerifyRoundTrip(%q): %v", art, err)
+		}
+		if len(mismatches) > 0 {
+			t.Errorf("VerifyRoundTrip(%q) = %v", art, mismatches)
+		}
+	}
+	// brackets can't be encoded, so art containing them can't round trip.
+	if _, err := VerifyRoundTrip("[#]"); err == nil {
+		t.Error("VerifyRoundTrip of art with brackets succeeded, want an error")
+	}
+}
+
+// art is full of backslashes, the messages show them as written like the lint messages do.
+func TestCompareTextQuoting(t *testing.T) {
+	got := CompareText("/\\", "/|")
+	want := []Mismatch{{Line: 1, Column: 2, Want: `'\'`, Got: "'|'"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("CompareText = %v, want %v", got, want)
+	}
+}
@@ -3,11 +3,36 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
 	"time"
+	"art/cli"
 	"art/server"
 )
 
 func main() {
+	// no arguments or "serve" start the web server, whatever stdin is, so it runs under systemd, nohup or a pipe.
+	// any other arguments run the commandline tool, piped input is decoded with a flag like 'cat art.txt | art -m'.
+	args := os.Args[1:]
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "serve") {
+		os.Exit(cli.Run(args))
+	}
+
+	if err := server.LoadTemplate("public/index.html"); err != nil {
+		if len(args) == 0 && !stdinIsTerminal() {
+			log.Fatalf("%v (without arguments the web interface starts, to decode piped input use 'art -m' or 'art -i -')", err)
+		}
+		log.Fatalf("%v (the web interface is started from the directory containing public/)", err)
+	}
+	// cypher history redaction can be configured with ART_HISTORY_KEYS and ART_HISTORY_PLAINTEXT.
+	if err := server.LoadHistoryPolicyFromEnv(); err != nil {
+		log.Fatal(err)
+	}
+
+	// the gallery indexes ./resources, ART_GALLERY_DIR points it to another directory.
+	if dir := os.Getenv("ART_GALLERY_DIR"); dir != "" {
+		server.ArtGallery = server.NewGallery(dir)
+	}
+
 	//serving static files from ./public html/css
 	fs := http.FileServer(http.Dir("./public"))
 	mux := http.NewServeMux()
@@ -16,8 +41,26 @@ func main() {
 	// register HTTP handlers for different endpoints:
 	// "/decoder" handles encoding/decoding POST requests
 	mux.HandleFunc("/decoder", server.CodecHandler)
+	// "/render" sends decoded art as a PNG or SVG download
+	mux.HandleFunc("/render", server.RenderHandler)
+	// "/convert" turns an uploaded image into art
+	mux.HandleFunc("/convert", server.ConvertHandler)
+	// "/unpack" handles uploaded art bundles
+	mux.HandleFunc("/unpack", server.UnpackHandler)
 	// "/cypher" handles cypher POST requests
 	mux.HandleFunc("/cypher", server.CypherHandler)
+	// "/api/cypher" is the JSON version of "/cypher"
+	mux.HandleFunc("/api/cypher", server.CypherAPIHandler)
+	// "/pipeline" runs a chain of steps, "/api/pipeline" is its JSON version
+	mux.HandleFunc("/pipeline", server.PipelineHandler)
+	mux.HandleFunc("/api/pipeline", server.PipelineAPIHandler)
+	// "/banner" renders text with a FIGlet font
+	mux.HandleFunc("/banner", server.BannerHandler)
+	// "/diff" compares two versions of the art
+	mux.HandleFunc("/diff", server.DiffHandler)
+	// "/gallery" shows the art of the gallery directory, "/api/gallery" lists it as JSON
+	mux.HandleFunc("/gallery", server.GalleryHandler)
+	mux.HandleFunc("/api/gallery", server.GalleryAPIHandler)
 	// "/" servers the main index page (GET requests)
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		// returns 404 for any path other than "/"
@@ -40,7 +83,10 @@ func main() {
 	if err != nil {
 		log.Fatal(err) // logs fatal error and stop if server fails to start.
 	}
-	
-
 }
 
+// stdinIsTerminal reports whether standard input is a terminal rather than a pipe or a file.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -14,6 +14,8 @@ type HistoryEntry struct {
 	Action		string
 	Input		string
 	Result		string
+
+	retention // only operations with cypher steps hold plaintext
 }
 
 var (
@@ -32,7 +34,9 @@ const (
 /*
 	handling /decoder POST requests for encoding and decoding.
 	- Accepts only POST requests
-	- Parses form inputs: encodeInput, decodeInput, and action.
+	- Parses form inputs: encodeInput, decodeInput, action, stripANSI (removes colour escape sequences),
+	  animation (the input is split into frames at the marker lines)
+	  and transforms (applied to the decoded art, or to the art before encoding).
 	- Validation through validateInputs().
 	- based on action -> calls processEncoding() or processDecoding().
 	- success -> updates CombinedDataPage with results and status.
@@ -63,6 +67,17 @@ func CodecHandler(w http.ResponseWriter, r *http.Request) {
 		rawDecodeInput := normalizeNewLines(r.FormValue("decodeInput"))
 		rawEncodeInput := normalizeNewLines(r.FormValue("encodeInput"))
 		action := r.FormValue("action")
+		data.StripANSI = r.FormValue("stripANSI") != ""
+		data.Animation = r.FormValue("animation") != ""
+		data.Transforms = r.FormValue("transforms")
+
+		transforms, err := functions.ParseTransforms(data.Transforms)
+		if err != nil {
+			data.DecodeInput = rawDecodeInput
+			data.EncodeInput = rawEncodeInput
+			respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, err.Error()), &data)
+			return
+		}
 
 		// validates inputs and gets any errors
 		errMsg, statusType, statusCode, decodeInput, encodeInput := validateInputs(action, rawDecodeInput, rawEncodeInput)
@@ -87,7 +102,14 @@ func CodecHandler(w http.ResponseWriter, r *http.Request) {
 		// process encoding or decoding based on action.
 		switch action {
 		case actionEncode:
-			result, err := processEncoding(data.EncodeInput)
+			if data.StripANSI {
+				data.EncodeInput = functions.StripANSI(data.EncodeInput)
+			}
+			if data.EncodeInput, err = applyTransforms(data.EncodeInput, transforms); err != nil {
+				respondWithError(w, http.StatusUnprocessableEntity, err.Error(), &data)
+				return
+			}
+			result, err := processEncoding(data.EncodeInput, data.Animation)
 			if err != nil {
 				// clears DecodeInput and respond with error on failure
 				data.DecodeInput = ""
@@ -104,13 +126,21 @@ func CodecHandler(w http.ResponseWriter, r *http.Request) {
 			
 
 		case actionDecode:
-			result, err := processDecoding(data.DecodeInput)
+			result, err := processDecoding(data.DecodeInput, data.Animation)
 			if err != nil {
 				// clears EncodeInput and respond with error on failure
 				data.EncodeInput = ""
 				respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgMalformedInput), &data)
                 return
 			}
+			// colours are removed from the decoded art on demand
+			if data.StripANSI {
+				result = functions.StripANSI(result)
+			}
+			if result, err = applyTransforms(result, transforms); err != nil {
+				respondWithError(w, http.StatusUnprocessableEntity, err.Error(), &data)
+				return
+			}
 			data.EncodeInput = result
 			data.StatusCode = http.StatusAccepted
 			data.StatusType = statusSuccess
@@ -129,18 +159,41 @@ func CodecHandler(w http.ResponseWriter, r *http.Request) {
 	// calculate the number of lines for dynamic text area sizing
 	data.LineCount = max(countLines(data.DecodeInput), countLines(data.EncodeInput))
 	
-	// safely copy history slice under lock to prevent concurrent access issues.
-	historyMutex.Lock()
-	data.History = make([]HistoryEntry, len(history))
-	copy(data.History, history)
-	historyMutex.Unlock()
+	// copy the history, expired plaintext is redacted first.
+	data.History = historySnapshot()
 
 	// render the updated page with encoding/decoding results and history.
 	renderTemplate(w, data)
 }
 
-// processEncoding calls the encoding function and handles errors
-func processEncoding(input string) (string, error) {
+// applyTransforms runs the transforms on the art, the result has to stay within the input limit.
+// the size is projected through the transforms first, so growing ones are refused before they allocate.
+func applyTransforms(art string, transforms []functions.Transform) (string, error) {
+	if len(transforms) == 0 {
+		return art, nil
+	}
+	if err := functions.CheckTransforms(art, transforms, maxPipelineDataLength); err != nil {
+		return "", errors.New(formatStatusMessage(http.StatusUnprocessableEntity, MsgResultTooLong))
+	}
+	result, err := functions.ApplyTransforms(art, transforms)
+	if err != nil {
+		return "", errors.New(formatStatusMessage(http.StatusUnprocessableEntity, err.Error()))
+	}
+	if inputExceedsLimit(result, MaxInputLength) {
+		return "", errors.New(formatStatusMessage(http.StatusUnprocessableEntity, MsgResultTooLong))
+	}
+	return result, nil
+}
+
+// processEncoding calls the encoding function and handles errors, animations are encoded frame by frame.
+func processEncoding(input string, animated bool) (string, error) {
+	if animated {
+		animation, err := functions.EncodeAnimation(input)
+		if err != nil {
+			return "", errors.New(MsgMalformedInput)
+		}
+		return animation.String(), nil
+	}
 	result := functions.EncodeString(input, false)
 	if result == errorString {
 		return "", errors.New(MsgMalformedInput)
@@ -149,7 +202,14 @@ func processEncoding(input string) (string, error) {
 }
 
 // same as above, but for decoding.
-func processDecoding(input string) (string, error) {
+func processDecoding(input string, animated bool) (string, error) {
+	if animated {
+		animation, err := functions.DecodeAnimation(input)
+		if err != nil {
+			return "", errors.New(MsgMalformedInput)
+		}
+		return animation.String(), nil
+	}
 	result := functions.DecodeString(input, false)
 	if result == errorString {
 		return "", errors.New(MsgMalformedInput)
@@ -189,15 +249,25 @@ func validateInputs(action, rawDecodeInput, rawEncodeInput string) (errMsg, stat
 }
 // saveHistory safely appends a new encode/decode operation to the history slice
 func saveHistory(action, input, result string) {
+	savePlaintextHistory(action, input, result, retention{})
+}
+
+// savePlaintextHistory appends an operation whose plaintext sides are kept as CypherHistoryPolicy allows,
+// expired plaintext of older entries is redacted on every write.
+func savePlaintextHistory(action, input, result string, plaintext retention) {
 	entry := HistoryEntry {
 		Timestamp: 	time.Now().Format("January 2, 15:04"),
 		Action: 	action,
 		Input:		input,
 		Result:		result,
+		retention:	plaintext,
 	}
+	CypherHistoryPolicy.keep(&entry.retention, &entry.Input, &entry.Result)
+
 	// lock before modifying shared history slice to prevent race conditions
 	historyMutex.Lock()
 	defer historyMutex.Unlock()
+	expireHistory(time.Now())
 
 	// insert new entry at the beginning of the slice.
 	history = append([]HistoryEntry{entry}, history...)
@@ -208,3 +278,22 @@ func saveHistory(action, input, result string) {
 	}
 }
 
+
+// historySnapshot redacts expired plaintext in the stored history and returns a copy of it.
+func historySnapshot() []HistoryEntry {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+	expireHistory(time.Now())
+
+	snapshot := make([]HistoryEntry, len(history))
+	copy(snapshot, history)
+	return snapshot
+}
+
+// expireHistory redacts the expired plaintext, the caller holds historyMutex.
+func expireHistory(now time.Time) {
+	for i := range history {
+		entry := &history[i]
+		entry.expire(now, &entry.Input, &entry.Result)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCodecHandlerTransformLimits(t *testing.T) {
+	art := strings.Repeat(strings.Repeat("#", 20)+"\n", 20)
+	tests := []struct {
+		name		string
+		transforms	string
+		want		int
+	}{
+		{"no transforms", "", http.StatusAccepted},
+		{"small scale", "scale:2", http.StatusAccepted},
+		{"huge pad", "pad:2000000000", http.StatusBadRequest},
+		{"chained scales", "scale:8,scale:8,scale:8", http.StatusUnprocessableEntity},
+		{"wide pad", "pad:1000", http.StatusUnprocessableEntity},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			form := url.Values{"action": {actionEncode}, "encodeInput": {art}, "transforms": {test.transforms}}
+			w := postForm(CodecHandler, "/decoder", form)
+			if w.Code != test.want {
+				t.Errorf("status %d, want %d", w.Code, test.want)
+			}
+		})
+	}
+}
+
+func TestCodecHandlerAnimation(t *testing.T) {
+	encoded := "@frame 120\n[3 -]\n@frame\n[3 =]"
+	tests := []struct {
+		name		string
+		animation	string
+		want		string
+	}{
+		{"marker lines are text", "", "@frame 120\n---\n@frame\n==="},
+		{"frames with the animation field", "1", "@frame 120\n---\n@frame 100\n==="},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			form := url.Values{"action": {actionDecode}, "decodeInput": {encoded}, "animation": {test.animation}}
+			w := postForm(CodecHandler, "/decoder", form)
+			if w.Code != http.StatusAccepted {
+				t.Fatalf("status %d, want %d", w.Code, http.StatusAccepted)
+			}
+			if body := w.Body.String(); !strings.Contains(body, html.EscapeString(test.want)) {
+				t.Errorf("the page doesn't show the decoded art %q", test.want)
+			}
+		})
+	}
+}
+
+func TestCodecHandlerDecodeLimits(t *testing.T) {
+	tests := []struct {
+		input	string
+		want	int
+	}{
+		{"[50 x]", http.StatusAccepted},
+		{"[5000 x]\n[5001 y]", http.StatusUnprocessableEntity},
+		{"[50000000 x]", http.StatusUnprocessableEntity},
+		{"[+50000000 x]", http.StatusBadRequest},
+		{"[99999999999999999999 x]", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			form := url.Values{"action": {actionDecode}, "decodeInput": {test.input}}
+			w := postForm(CodecHandler, "/decoder", form)
+			if w.Code != test.want {
+				t.Errorf("status %d, want %d", w.Code, test.want)
+			}
+			if w.Body.Len() > 1<<20 {
+				t.Errorf("response of %d bytes", w.Body.Len())
+			}
+		})
+	}
+}
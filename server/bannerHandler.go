@@ -0,0 +1,92 @@
+package server
+
+import (
+	"art/functions"
+	"log"
+	"net/http"
+)
+
+const maxBannerTextLength = 200 // banners grow fast, so the text is kept short
+
+/*
+	BannerHandler handles /banner POST requests.
+		- expects 'text', optional 'font', 'layout' and 'encode' form values.
+		- renders the text in big letters with a bundled FIGlet font.
+		- with 'encode' the banner is shown in its encoded form.
+		- the banner is saved in the history.
+*/
+func BannerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		log.Printf("bannerHandler: Method Not Allowed: received %s, only POST allowed", r.Method)
+		http.Error(w, MsgMethodNotAllowed, http.StatusMethodNotAllowed)
+		return
+	}
+	data := CombinedPageData{
+		Section: "banner",
+	}
+
+	text := normalizeNewLines(r.FormValue("text"))
+	opts := functions.BannerOptions{
+		Font:	r.FormValue("font"),
+		Layout:	r.FormValue("layout"),
+	}
+	encode := r.FormValue("encode") != ""
+
+	data.BannerText = text
+	data.BannerFont = opts.Font
+	data.BannerLayout = opts.Layout
+	data.BannerEncode = encode
+
+	if text == "" {
+		respondWithError(w, http.StatusBadRequest, MsgInputEmpty, &data)
+		return
+	}
+	if inputExceedsLimit(text, maxBannerTextLength) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgBannerTooLong), &data)
+		return
+	}
+	// only bundled fonts, paths to font files are not accepted from users.
+	if !isBundledFont(opts.Font) {
+		respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgUnknownFont), &data)
+		return
+	}
+
+	banner, err := functions.Banner(text, opts)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, err.Error()), &data)
+		return
+	}
+	action := "banner " + opts.Font
+	if encode {
+		banner = functions.EncodeString(banner, true)
+		if banner == errorString {
+			respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, MsgMalformedInput), &data)
+			return
+		}
+		action += " (encoded)"
+	}
+	saveHistory(action, text, banner)
+
+	data.BannerResult = banner
+	data.StatusCode = http.StatusOK
+	data.StatusType = statusSuccess
+	data.StatusMessage = formatStatusMessage(http.StatusOK, "banner created")
+	data.LineCount = countLines(banner)
+
+	data.History = historySnapshot()
+
+	renderTemplate(w, data)
+}
+
+// isBundledFont reports whether name is one of the bundled fonts (empty means the default font).
+func isBundledFont(name string) bool {
+	if name == "" {
+		return true
+	}
+	for _, font := range functions.FontNames() {
+		if font == name {
+			return true
+		}
+	}
+	return false
+}
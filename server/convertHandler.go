@@ -0,0 +1,87 @@
+package server
+
+import (
+	"art/functions"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	maxImageUploadSize	= 8 << 20 // max size of an uploaded image
+	maxConvertWidth		= 120     // max art width, keeps the result within MaxInputLength for most images
+)
+
+/*
+	ConvertHandler handles /convert POST requests with an uploaded image.
+		- expects multipart form data with an 'image' (PNG, JPEG or GIF).
+		- optional 'width', 'ramp', 'dither', 'edges' and 'invert' form values.
+		- the art is shown in the result field and its encoded form in the decode field.
+		- the conversion is saved in the history.
+*/
+func ConvertHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		log.Printf("convertHandler: Method Not Allowed: received %s, only POST allowed", r.Method)
+		http.Error(w, MsgMethodNotAllowed, http.StatusMethodNotAllowed)
+		return
+	}
+	data := CombinedPageData{
+		Section: "decoder",
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageUploadSize)
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgImageTooLarge), &data)
+			return
+		}
+		log.Printf("convertHandler: %s: %v", MsgFailedToParseForm, err)
+		respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgNoFileUploaded), &data)
+		return
+	}
+	defer file.Close()
+
+	opts := functions.DefaultConvertOptions()
+	if width := r.FormValue("width"); width != "" {
+		opts.Width, err = strconv.Atoi(width)
+		if err != nil || opts.Width < 1 || opts.Width > maxConvertWidth {
+			respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgInvalidWidth), &data)
+			return
+		}
+	}
+	if ramp := r.FormValue("ramp"); ramp != "" {
+		opts.Ramp = ramp
+	}
+	opts.Dither = r.FormValue("dither") != ""
+	opts.Edges = r.FormValue("edges") != ""
+	opts.Invert = r.FormValue("invert") != ""
+	// tall images are refused before they are sampled, the result has to fit into the decoder.
+	opts.MaxSize = MaxInputLength
+
+	art, err := functions.ConvertImage(file, opts)
+	if err != nil {
+		respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, err.Error()), &data)
+		return
+	}
+	if inputExceedsLimit(art, MaxInputLength) {
+		respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, MsgResultTooLong), &data)
+		return
+	}
+	encoded := functions.EncodeString(art, true)
+
+	saveHistory("convert", header.Filename, art)
+
+	data.EncodeInput = art
+	data.DecodeInput = encoded
+	data.StatusCode = http.StatusOK
+	data.StatusType = statusSuccess
+	data.StatusMessage = formatStatusMessage(http.StatusOK, "image converted to art")
+	data.LineCount = max(countLines(art), countLines(encoded))
+
+	data.History = historySnapshot()
+
+	renderTemplate(w, data)
+}
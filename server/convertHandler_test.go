@@ -0,0 +1,52 @@
+package server
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// uploadImage posts a blank PNG of the given size to ConvertHandler.
+func uploadImage(t *testing.T, width, height int) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("image", "image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	form.WriteField("width", "120")
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/convert", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	ConvertHandler(w, r)
+	return w
+}
+
+func TestConvertHandlerLimits(t *testing.T) {
+	tests := []struct {
+		name			string
+		width, height	int
+		want			int
+	}{
+		{"landscape", 240, 80, http.StatusOK},
+		{"tall and narrow", 1, 4096, http.StatusUnprocessableEntity},
+		{"too many pixels", 5000, 5000, http.StatusUnprocessableEntity},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if w := uploadImage(t, test.width, test.height); w.Code != test.want {
+				t.Errorf("status %d, want %d", w.Code, test.want)
+			}
+		})
+	}
+}
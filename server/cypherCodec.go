@@ -8,12 +8,15 @@ import (
 	"time"
 )
 // CypherHistoryEntry stores details of each XOR or ROT13 opeation perfomed by the user.
+// the key itself is never stored, see HistoryPolicy.
 type CypherHistoryEntry struct {
-	Timestamp 	string
-	Mode		string
-	Key			string //optional: used for XOR
-	Input		string
-	Result		string
+	Timestamp 		string
+	Mode			string
+	KeyFingerprint	string //optional: short hash of the XOR key
+	Input			string
+	Result			string
+
+	retention
 }
 
 // operation identifiers.
@@ -30,6 +33,7 @@ var (
 	handles post requests for XOR and ROT13
 		must be x-www-form-urlencoded and contain
 			- expects 'mode' (xor or rot13), 'key' (for xor) and 'input' (data to process) form values.
+			- optional 'encoding' (base64, base64url, hex, base32) and 'direction' (encrypt, decrypt, auto) for xor.
 			- validates inputs for presence and length.
 			- calls corresponding function for the requested mode.
 			- records the operation in history.
@@ -48,109 +52,190 @@ func CypherHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// extract form inputs
-	mode := r.FormValue("mode")
-	key := r.FormValue("key")
-	rawInput := normalizeNewLines(r.FormValue("input"))
+	req := cypherRequest{
+		Mode:      r.FormValue("mode"),
+		Key:       r.FormValue("key"),
+		Input:     normalizeNewLines(r.FormValue("input")),
+		Encoding:  r.FormValue("encoding"),
+		Direction: r.FormValue("direction"),
+		Sensitive: r.FormValue("sensitive") != "",
+	}
+
+	// prepopulate the form fields for response rendering
+	data.Input = req.Input
+	data.Key = req.Key
+	data.Mode = req.Mode
+	data.Encoding = req.Encoding
+	data.Direction = req.Direction
+	data.Sensitive = req.Sensitive
+
+	result, code, errMsg := processCypher(&req)
+	if errMsg != "" {
+		respondWithError(w, code, errMsg, &data)
+		return
+	}
+
+	// prepare success response: clear input field, display result
+	data.Input = ""
+	data.Result = result
+	data.Encoding = req.Encoding
+	data.StatusCode = http.StatusOK
+	data.StatusType = statusSuccess
+	data.StatusMessage = formatStatusMessage(http.StatusOK, "successfully encrypted/decrypted")
+	data.LineCount = countLines(result)
+
+	// copy the history with expired plaintext redacted to safely pass to template
+	data.CypherHistory = cypherHistorySnapshot()
+
+	// render the template with updated data and history.
+	renderTemplate(w, data)
+	
+}
 
+// cypherRequest holds the inputs of a single cypher operation, shared by the form and the JSON API.
+type cypherRequest struct {
+	Mode		string `json:"mode"`
+	Key			string `json:"key"`
+	Input		string `json:"input"`
+	Encoding	string `json:"encoding"`	// cyphertext encoding for XOR, defaults to base64
+	Direction	string `json:"direction"`	// encrypt, decrypt or auto (default)
+	Sensitive	bool   `json:"sensitive"`	// keeps the operation out of the history
+}
+
+// cypherResponse is the JSON body returned by CypherAPIHandler.
+type cypherResponse struct {
+	Mode		string `json:"mode,omitempty"`
+	Encoding	string `json:"encoding,omitempty"`
+	Result		string `json:"result,omitempty"`
+	Error		string `json:"error,omitempty"`
+}
+
+/*
+	processCypher validates the request and runs the requested cypher.
+	- returns the result on success.
+	- on failure returns the HTTP status code and message to show to the user.
+	- successful operations are recorded in the cypher history.
+	- req.Encoding is replaced with the resolved encoding name.
+*/
+func processCypher(req *cypherRequest) (string, int, string) {
 	// validate that input is not empty
-	if rawInput == "" {
+	if req.Input == "" {
 		log.Printf("cypherHandler: Empty input received")
-		respondWithError(w, http.StatusBadRequest, MsgInputEmpty, &data)
-		return
+		return "", http.StatusBadRequest, MsgInputEmpty
 	}
 
 	// if XOR mode selected, validate key is provided and not too long
-	if mode == xor {
-		if key == "" {
+	if req.Mode == xor {
+		if req.Key == "" {
 			log.Printf("cypherHandler: XOR mode selected but empty key provided")
-			respondWithError(w, http.StatusBadRequest, MsgXOREmpty, &data)
-			return
+			return "", http.StatusBadRequest, MsgXOREmpty
 		}
-		if inputExceedsLimit(key, maxKeyLength) {
-			respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, "XOR key is too long"), &data)
-			log.Printf("cypherHandler: XOR key too long: %d characters", len(key))
-			return
+		if inputExceedsLimit(req.Key, maxKeyLength) {
+			log.Printf("cypherHandler: XOR key too long: %d characters", len(req.Key))
+			return "", http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, "XOR key is too long")
 		}
 	}
 
 	// validate input length to avoid excessive processing or abuse
-	if inputExceedsLimit(rawInput, MaxInputLength) {
-		respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgInputTooLong), &data)
-		log.Printf("cypherHandler: Input too long: %d characters", len(rawInput))
-		return
+	if inputExceedsLimit(req.Input, MaxInputLength) {
+		log.Printf("cypherHandler: Input too long: %d characters", len(req.Input))
+		return "", http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgInputTooLong)
 	}
 
-	// prepopulate the form fields for response rendering
-	data.Input = rawInput
-	data.Key = key
-	
-	var result string
-
 	// process input depending on mode
-	switch mode {
+	switch req.Mode {
 	case xor:
+		encoding, err := functions.ParseEncoding(req.Encoding)
+		if err != nil {
+			return "", http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, err.Error())
+		}
+		// raw bytes can't be shown in a textarea or a JSON string reliably.
+		if encoding == functions.EncodingRaw {
+			return "", http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgRawEncoding)
+		}
+		req.Encoding = encoding
+		direction := functions.ResolveDirection(req.Input, encoding, req.Direction)
+
 		// perform XOR encryption/decryption with the provided key
-		res, err := functions.Xorify(rawInput, key)
+		result, err := functions.XorifyWith(req.Input, []byte(req.Key), encoding, direction)
 		if err != nil {
 			log.Printf("cypherHandler: XOR error: %v", err)
-			respondWithError(w, http.StatusInternalServerError, MsgInternalServerError, &data)
-			return
+			return "", http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, err.Error())
+		}
+		// save successful operation to history, unless marked sensitive
+		if !req.Sensitive {
+			encrypt := direction == functions.DirectionEncrypt
+			saveCypherHistory(xor, req.Key, req.Input, result, retention{plaintextInput: encrypt, plaintextResult: !encrypt})
 		}
-		result = res
-		data.Mode = xor
-		// save successful operation to history
-		saveCypherHistory(xor, key, rawInput, result)
+		return result, http.StatusOK, ""
 
 	case rot13:
 		// perform ROT13 encryption/decryption
-		result = functions.Rot13ify(rawInput)
-		data.Mode = rot13
-		// save successfull operation to history
-		saveCypherHistory(rot13, "", rawInput, result)
-		
+		result := functions.Rot13ify(req.Input)
+		// save successfull operation to history, anyone can reverse ROT13 so both sides are plaintext
+		if !req.Sensitive {
+			saveCypherHistory(rot13, "", req.Input, result, retention{plaintextInput: true, plaintextResult: true})
+		}
+		return result, http.StatusOK, ""
+
 	default:
 		// invalid mode, respond with error
-		log.Printf("cypherHandler: Invalid mode received: %s", mode)
-		respondWithError(w, http.StatusBadRequest, MsgInvalidAction, &data)
-		return
+		log.Printf("cypherHandler: Invalid mode received: %s", req.Mode)
+		return "", http.StatusBadRequest, MsgInvalidAction
 	}
+}
 
-	// prepare success response: clear input field, display result
-	data.Input = ""
-	data.Result = result
-	data.StatusCode = http.StatusOK
-	data.StatusType = statusSuccess
-	data.StatusMessage = formatStatusMessage(http.StatusOK, "successfully encrypted/decrypted")
-	data.LineCount = countLines(result)
+/*
+	CypherAPIHandler is the JSON version of CypherHandler.
+		- expects POST with a JSON body: {"mode", "key", "input", "encoding", "direction"}.
+		- responds with {"mode", "encoding", "result"} or {"error"} and a matching status code.
+*/
+func CypherAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, cypherResponse{Error: MsgMethodNotAllowed})
+		return
+	}
 
-	// copy the history slice under mutex lock to safely pass to template
-	cypherHistoryMutex.Lock()
-	data.CypherHistory = make([]CypherHistoryEntry, len(cypherHistory))
-	copy(data.CypherHistory, cypherHistory)
-	cypherHistoryMutex.Unlock()
+	var req cypherRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		log.Printf("cypherAPIHandler: %v", err)
+		writeJSON(w, http.StatusBadRequest, cypherResponse{Error: MsgInvalidJSON})
+		return
+	}
+	req.Input = normalizeNewLines(req.Input)
 
-	// render the template with updated data and history.
-	renderTemplate(w, data)
-	
+	result, code, errMsg := processCypher(&req)
+	if errMsg != "" {
+		writeJSON(w, code, cypherResponse{Error: errMsg})
+		return
+	}
+	writeJSON(w, http.StatusOK, cypherResponse{Mode: req.Mode, Encoding: req.Encoding, Result: result})
 }
 
 /* 
 	saveCypherHistory appends a new cypher operation record to the history buffer.
+	- the key is reduced to a fingerprint or dropped, depending on CypherHistoryPolicy.
+	- plaintext (the sides marked in plaintext) is kept for the retention period only.
+	- expired plaintext of older entries is redacted on every write.
 	- uses a mutex to avoid concurrent access issues.
 	- keeps the newest entries ath the front of the slice.
 	- truncates the history to the last MaxHistoryEntries to limit memory usage.
 */
-func saveCypherHistory(mode, key, input, result string) {
+func saveCypherHistory(mode, key, input, result string, plaintext retention) {
+	policy := CypherHistoryPolicy
 	entry := CypherHistoryEntry {
-		Timestamp: 	time.Now().Format("January 2, 15:04"),
-		Mode:		mode,
-		Key:		key,
-		Input:		input,
-		Result:		result,
+		Timestamp: 		time.Now().Format("January 2, 15:04"),
+		Mode:			mode,
+		KeyFingerprint:	policy.keyFingerprint(key),
+		Input:			input,
+		Result:			result,
+		retention:		plaintext,
 	}
+	policy.keep(&entry.retention, &entry.Input, &entry.Result)
 
 	cypherHistoryMutex.Lock()
 	defer cypherHistoryMutex.Unlock()
+	expireCypherHistory(time.Now())
 
 	// insert new entry at the beginning of the slice.
 	cypherHistory = append([]CypherHistoryEntry{entry}, cypherHistory...)
@@ -159,4 +244,23 @@ func saveCypherHistory(mode, key, input, result string) {
 	if len(cypherHistory) > MaxHistoryEntries {
 		cypherHistory = cypherHistory[:MaxHistoryEntries]
 	}
-}
\ No newline at end of file
+}
+
+// cypherHistorySnapshot redacts expired plaintext in the stored history and returns a copy of it.
+func cypherHistorySnapshot() []CypherHistoryEntry {
+	cypherHistoryMutex.Lock()
+	defer cypherHistoryMutex.Unlock()
+	expireCypherHistory(time.Now())
+
+	snapshot := make([]CypherHistoryEntry, len(cypherHistory))
+	copy(snapshot, cypherHistory)
+	return snapshot
+}
+
+// expireCypherHistory redacts the expired plaintext, the caller holds cypherHistoryMutex.
+func expireCypherHistory(now time.Time) {
+	for i := range cypherHistory {
+		entry := &cypherHistory[i]
+		entry.expire(now, &entry.Input, &entry.Result)
+	}
+}
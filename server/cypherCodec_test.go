@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// postCypher sends the JSON body to the cypher API and returns the status code and response.
+func postCypher(t *testing.T, body string) (int, cypherResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	CypherAPIHandler(w, httptest.NewRequest(http.MethodPost, "/api/cypher", strings.NewReader(body)))
+	var resp cypherResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, resp
+}
+
+func TestCypherAPI(t *testing.T) {
+	tests := []struct {
+		name	string
+		req		cypherRequest
+		want	int
+		result	string
+	}{
+		{"xor hex", cypherRequest{Mode: xor, Key: "key", Input: "hi!", Encoding: "hex", Direction: "encrypt", Sensitive: true}, http.StatusOK, "030c58"},
+		{"xor auto decrypt", cypherRequest{Mode: xor, Key: "key", Input: "030c58", Encoding: "hex", Sensitive: true}, http.StatusOK, "hi!"},
+		{"rot13", cypherRequest{Mode: rot13, Input: "art", Sensitive: true}, http.StatusOK, "neg"},
+		{"empty input", cypherRequest{Mode: rot13}, http.StatusBadRequest, ""},
+		{"empty key", cypherRequest{Mode: xor, Input: "hi"}, http.StatusBadRequest, ""},
+		{"key too long", cypherRequest{Mode: xor, Key: strings.Repeat("k", maxKeyLength+1), Input: "hi"}, http.StatusRequestEntityTooLarge, ""},
+		{"input too long", cypherRequest{Mode: rot13, Input: strings.Repeat("a", MaxInputLength+1)}, http.StatusRequestEntityTooLarge, ""},
+		{"unknown encoding", cypherRequest{Mode: xor, Key: "key", Input: "hi", Encoding: "base58"}, http.StatusBadRequest, ""},
+		{"raw encoding", cypherRequest{Mode: xor, Key: "key", Input: "hi", Encoding: "raw"}, http.StatusBadRequest, ""},
+		{"invalid cyphertext", cypherRequest{Mode: xor, Key: "key", Input: "hi", Encoding: "hex", Direction: "decrypt"}, http.StatusBadRequest, ""},
+		{"unknown mode", cypherRequest{Mode: "caesar", Input: "hi"}, http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := json.Marshal(test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, resp := postCypher(t, string(body))
+			if code != test.want {
+				t.Fatalf("status %d (%s), want %d", code, resp.Error, test.want)
+			}
+			if resp.Result != test.result {
+				t.Errorf("result %q, want %q", resp.Result, test.result)
+			}
+		})
+	}
+}
+
+func TestCypherAPIRejectsInvalidJSON(t *testing.T) {
+	for _, body := range []string{"", "{", `{"mode": "rot13", "input": "hi", "colour": "red"}`} {
+		if code, _ := postCypher(t, body); code != http.StatusBadRequest {
+			t.Errorf("body %q: status %d, want %d", body, code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"art/functions"
+	"log"
+	"net/http"
+)
+
+// limits of the compared grid, the side by side view has a cell for every character of both versions.
+const (
+	maxDiffColumns	= 1000
+	maxDiffRows		= 1000
+	maxDiffCells	= 100000
+)
+
+/*
+	DiffHandler handles /diff POST requests.
+		- expects 'old' and 'new' form values, plain or encoded art (encoded art is decoded first).
+		- compares both versions cell by cell, grids beyond maxDiffColumns, maxDiffRows or maxDiffCells are refused.
+		- renders a side by side view with the changed cells highlighted and a unified diff.
+*/
+func DiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		log.Printf("diffHandler: Method Not Allowed: received %s, only POST allowed", r.Method)
+		http.Error(w, MsgMethodNotAllowed, http.StatusMethodNotAllowed)
+		return
+	}
+	data := CombinedPageData{
+		Section: "diff",
+	}
+
+	data.DiffOld = normalizeNewLines(r.FormValue("old"))
+	data.DiffNew = normalizeNewLines(r.FormValue("new"))
+	data.LineCount = max(countLines(data.DiffOld), countLines(data.DiffNew))
+
+	if data.DiffOld == "" && data.DiffNew == "" {
+		respondWithError(w, http.StatusBadRequest, MsgInputEmpty, &data)
+		return
+	}
+	if inputExceedsLimit(data.DiffOld, MaxInputLength) || inputExceedsLimit(data.DiffNew, MaxInputLength) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgInputTooLong), &data)
+		return
+	}
+	// encoded art can expand a lot, the decoded versions have the same limit as the decoder.
+	if decodedExceedsLimit(data.DiffOld, MaxInputLength) || decodedExceedsLimit(data.DiffNew, MaxInputLength) {
+		respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, MsgResultTooLong), &data)
+		return
+	}
+
+	oldArt, newArt := functions.DecodeIfEncoded(data.DiffOld), functions.DecodeIfEncoded(data.DiffNew)
+	if diffGridExceedsLimit(oldArt, newArt) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgDiffTooLarge), &data)
+		return
+	}
+	diff := functions.DiffArt(oldArt, newArt)
+	data.DiffRows = diff.Rows()
+	data.DiffUnified = diff.Unified("old", "new")
+	data.StatusCode = http.StatusOK
+	data.StatusType = statusSuccess
+	data.StatusMessage = formatStatusMessage(http.StatusOK, diff.Summary())
+
+	renderTemplate(w, data)
+}
+
+// diffGridExceedsLimit reports whether the grid covering both versions is too large to compare.
+func diffGridExceedsLimit(oldArt, newArt string) bool {
+	oldWidth, oldHeight := functions.ArtDimensions(oldArt)
+	newWidth, newHeight := functions.ArtDimensions(newArt)
+	width, height := max(oldWidth, newWidth), max(oldHeight, newHeight)
+	return width > maxDiffColumns || height > maxDiffRows || width*height > maxDiffCells
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDiffHandlerLimits(t *testing.T) {
+	tests := []struct {
+		name	string
+		old		string
+		new		string
+		want	int
+	}{
+		{"small", "#-#", "#=#", http.StatusOK},
+		{"empty", "", "", http.StatusBadRequest},
+		{"too long", strings.Repeat("#", MaxInputLength+1), "#", http.StatusRequestEntityTooLarge},
+		{"long line against empty lines", strings.Repeat("#", 5000), strings.Repeat("\n", 4999), http.StatusRequestEntityTooLarge},
+		{"too many columns", strings.Repeat("#", maxDiffColumns+1), "#", http.StatusRequestEntityTooLarge},
+		{"too many rows", "#", strings.Repeat("#\n", maxDiffRows+1), http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := postForm(DiffHandler, "/diff", url.Values{"old": {test.old}, "new": {test.new}})
+			if w.Code != test.want {
+				t.Errorf("status %d, want %d: %s", w.Code, test.want, firstLine(w.Body.String()))
+			}
+		})
+	}
+}
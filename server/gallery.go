@@ -0,0 +1,233 @@
+package server
+
+import (
+	"art/functions"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// file name suffixes of the two forms of a gallery piece.
+const (
+	artSuffix		= ".art.txt"
+	encodedSuffix	= ".encoded.txt"
+
+	maxGalleryFileSize	= 1 << 20 // larger files, and encoded files decoding to more, are skipped when indexing
+)
+
+// GalleryPiece is one piece of art in the gallery, built from name.art.txt and/or name.encoded.txt.
+type GalleryPiece struct {
+	Name				string  `json:"name"`				// file name without the suffix
+	Title				string  `json:"title"`
+	Width				int     `json:"width"`				// widest line, in characters
+	Height				int     `json:"height"`				// number of lines
+	ArtSize				int     `json:"artSize"`			// decoded size in bytes
+	EncodedSize			int     `json:"encodedSize"`		// encoded size in bytes
+	CompressionRatio	float64 `json:"compressionRatio"`	// encoded size / decoded size
+	Art					string  `json:"art"`
+	Encoded				string  `json:"encoded"`
+}
+
+/*
+	Gallery indexes the art files of a directory.
+	- the index is rebuilt when a file is added, removed or changed,
+	  so pieces can be edited on disk while the server runs.
+	- a piece only needs one of its two files, the other form is computed.
+*/
+type Gallery struct {
+	dir			string
+	mu			sync.Mutex
+	signature	string // names, sizes and modification times of the indexed files
+	pieces		[]GalleryPiece
+}
+
+// ArtGallery is the gallery served on /gallery, ART_GALLERY_DIR points it to another directory.
+var ArtGallery = NewGallery("resources")
+
+// NewGallery returns a gallery for the art files in dir, nothing is read until it is used.
+func NewGallery(dir string) *Gallery {
+	return &Gallery{dir: dir}
+}
+
+// Pieces returns the pieces sorted by name, reindexing the directory if its files changed.
+func (g *Gallery) Pieces() ([]GalleryPiece, error) {
+	files, signature, err := g.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if signature != g.signature {
+		g.pieces = g.index(files)
+		g.signature = signature
+	}
+	return g.pieces, nil
+}
+
+// scan lists the art files of the directory with a signature that changes whenever one of them does.
+func (g *Gallery) scan() ([]string, string, error) {
+	entries, err := os.ReadDir(g.dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading gallery directory: %w", err)
+	}
+	var files []string
+	var signature strings.Builder
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (!strings.HasSuffix(name, artSuffix) && !strings.HasSuffix(name, encodedSuffix)) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// the file was removed since the directory was read.
+			continue
+		}
+		files = append(files, name)
+		fmt.Fprintf(&signature, "%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return files, signature.String(), nil
+}
+
+// index reads the files and pairs them into pieces, unreadable or invalid files are logged and skipped.
+func (g *Gallery) index(files []string) []GalleryPiece {
+	byName := map[string]*GalleryPiece{}
+	for _, file := range files {
+		content, err := readGalleryFile(filepath.Join(g.dir, file))
+		if err != nil {
+			log.Printf("gallery: %v", err)
+			continue
+		}
+		name, isArt := strings.TrimSuffix(file, artSuffix), strings.HasSuffix(file, artSuffix)
+		if !isArt {
+			name = strings.TrimSuffix(file, encodedSuffix)
+		}
+		piece, ok := byName[name]
+		if !ok {
+			piece = &GalleryPiece{Name: name, Title: galleryTitle(name)}
+			byName[name] = piece
+		}
+		if isArt {
+			piece.Art = content
+		} else {
+			piece.Encoded = content
+		}
+	}
+
+	pieces := make([]GalleryPiece, 0, len(byName))
+	for _, piece := range byName {
+		if err := completePiece(piece); err != nil {
+			log.Printf("gallery: %s: %v", piece.Name, err)
+			continue
+		}
+		pieces = append(pieces, *piece)
+	}
+	sort.Slice(pieces, func(i, j int) bool { return pieces[i].Name < pieces[j].Name })
+	return pieces
+}
+
+// readGalleryFile reads a gallery file, refusing files that are too large to show.
+func readGalleryFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxGalleryFileSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", path, maxGalleryFileSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return normalizeNewLines(string(data)), nil
+}
+
+// completePiece computes the missing form of the piece and its statistics.
+func completePiece(piece *GalleryPiece) error {
+	switch {
+	case piece.Encoded == "":
+		encoded := functions.EncodeString(strings.TrimSuffix(piece.Art, "\n"), true)
+		if encoded == errorString {
+			return fmt.Errorf("art can't be encoded")
+		}
+		piece.Encoded = encoded
+	case piece.Art == "":
+		// like the handlers, the decoded size is checked first, so '[999999999 #]' can't fill the memory.
+		if decodedExceedsLimit(piece.Encoded, maxGalleryFileSize) {
+			return fmt.Errorf("decodes to more than %d bytes", maxGalleryFileSize)
+		}
+		art := functions.DecodeString(piece.Encoded, true)
+		if art == errorString {
+			return fmt.Errorf("%s", MsgMalformedInput)
+		}
+		piece.Art = art
+	}
+
+	piece.Width, piece.Height = functions.ArtDimensions(piece.Art)
+	piece.ArtSize = len(piece.Art)
+	piece.EncodedSize = len(piece.Encoded)
+	if piece.ArtSize > 0 {
+		piece.CompressionRatio = float64(piece.EncodedSize) / float64(piece.ArtSize)
+	}
+	return nil
+}
+
+// CompressionPercent returns the encoded size as a rounded percentage of the decoded size.
+func (piece GalleryPiece) CompressionPercent() int {
+	return int(math.Round(piece.CompressionRatio * 100))
+}
+
+// galleryTitle turns a file name like "paper-plane" into "Paper plane".
+func galleryTitle(name string) string {
+	title := []rune(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+	if len(title) > 0 {
+		title[0] = unicode.ToUpper(title[0])
+	}
+	return string(title)
+}
+
+// galleryResponse is the JSON body returned by GalleryAPIHandler.
+type galleryResponse struct {
+	Pieces	[]GalleryPiece `json:"pieces"`
+	Error	string         `json:"error,omitempty"`
+}
+
+/*
+	GalleryHandler handles /gallery GET requests.
+		- renders the page with the gallery tab open.
+		- the pieces themselves come from CombinedPageData.GalleryPieces.
+*/
+func GalleryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		log.Printf("galleryHandler: Method Not Allowed: received %s, only GET allowed", r.Method)
+		http.Error(w, MsgMethodNotAllowed, http.StatusMethodNotAllowed)
+		return
+	}
+	renderTemplate(w, CombinedPageData{Section: "gallery", LineCount: 4})
+}
+
+/*
+	GalleryAPIHandler is the JSON version of GalleryHandler.
+		- expects GET.
+		- responds with {"pieces"}, every piece with its statistics, art and encoded form.
+*/
+func GalleryAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, galleryResponse{Error: MsgMethodNotAllowed})
+		return
+	}
+	pieces, err := ArtGallery.Pieces()
+	if err != nil {
+		log.Printf("galleryAPIHandler: %v", err)
+		writeJSON(w, http.StatusInternalServerError, galleryResponse{Error: MsgGalleryUnavailable})
+		return
+	}
+	writeJSON(w, http.StatusOK, galleryResponse{Pieces: pieces})
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGallerySkipsInvalidPieces(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lion.encoded.txt":		"[3 #]\n[2 #]",
+		"tree.art.txt":			"  ^\n /|\\",
+		"bomb.encoded.txt":		"[999999999 #]",
+		"signed.encoded.txt":	"[+999999999 #]",
+		"broken.encoded.txt":	"[3 #",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pieces, err := NewGallery(dir).Pieces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, piece := range pieces {
+		names = append(names, piece.Name)
+	}
+	if want := []string{"lion", "tree"}; !slices.Equal(names, want) {
+		t.Errorf("gallery pieces %v, want %v", names, want)
+	}
+	if pieces[0].Art != "###\n##" {
+		t.Errorf("decoded art %q", pieces[0].Art)
+	}
+}
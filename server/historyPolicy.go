@@ -0,0 +1,141 @@
+package server
+
+import (
+	"art/functions"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// key modes for the cypher history.
+const (
+	KeyFingerprint	= "fingerprint"	// stores the first bytes of the key's sha256 hash
+	KeyNone			= "none"		// stores nothing about the key
+
+	redactedText	= "[redacted]"	// shown in place of plaintext that is no longer kept
+	fingerprintSize	= 8				// bytes of the hash kept in a fingerprint
+)
+
+/*
+	HistoryPolicy decides what the cypher history, and the art history for operations with cypher steps, keeps.
+	- KeyMode: KeyFingerprint or KeyNone, the raw key is never stored.
+	- PlaintextRetention: how long plaintext stays in the history,
+	  0 never stores it, negative keeps it until the entry falls out of the history.
+*/
+type HistoryPolicy struct {
+	KeyMode				string
+	PlaintextRetention	time.Duration
+}
+
+// CypherHistoryPolicy is the policy used by the cypher handlers.
+var CypherHistoryPolicy = HistoryPolicy{
+	KeyMode:			KeyFingerprint,
+	PlaintextRetention:	10 * time.Minute,
+}
+
+/*
+	LoadHistoryPolicyFromEnv updates CypherHistoryPolicy from environment variables:
+	- ART_HISTORY_KEYS: "fingerprint" or "none".
+	- ART_HISTORY_PLAINTEXT: a duration like "30m", "off" to never store plaintext or "keep" to keep it.
+	unset variables leave the defaults in place.
+*/
+func LoadHistoryPolicyFromEnv() error {
+	policy := CypherHistoryPolicy
+
+	if mode, ok := os.LookupEnv("ART_HISTORY_KEYS"); ok {
+		mode = strings.ToLower(strings.TrimSpace(mode))
+		if mode != KeyFingerprint && mode != KeyNone {
+			return fmt.Errorf("ART_HISTORY_KEYS must be %q or %q, got %q", KeyFingerprint, KeyNone, mode)
+		}
+		policy.KeyMode = mode
+	}
+
+	if retention, ok := os.LookupEnv("ART_HISTORY_PLAINTEXT"); ok {
+		switch retention = strings.ToLower(strings.TrimSpace(retention)); retention {
+		case "off":
+			policy.PlaintextRetention = 0
+		case "keep":
+			policy.PlaintextRetention = -1
+		default:
+			duration, err := time.ParseDuration(retention)
+			if err != nil || duration <= 0 {
+				return fmt.Errorf("ART_HISTORY_PLAINTEXT must be a positive duration, \"off\" or \"keep\", got %q", retention)
+			}
+			policy.PlaintextRetention = duration
+		}
+	}
+
+	CypherHistoryPolicy = policy
+	return nil
+}
+
+// keyFingerprint returns what the history stores for the key, empty when there is no key or KeyMode is KeyNone.
+func (policy HistoryPolicy) keyFingerprint(key string) string {
+	if key == "" || policy.KeyMode == KeyNone {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:fingerprintSize])
+}
+
+// retention tracks the plaintext of a history entry, every entry goes through HistoryPolicy.keep when it is saved.
+type retention struct {
+	plaintextInput	bool		// the input is plaintext
+	plaintextResult	bool		// the result is plaintext
+	plaintextExpiry	time.Time	// the plaintext is redacted after this time, zero keeps it
+}
+
+// keep applies the policy to a new entry: its plaintext is redacted at once or gets an expiry.
+// an expiry schedules expireHistories, so plaintext never outlives it even when nothing is saved or shown.
+func (policy HistoryPolicy) keep(r *retention, input, result *string) {
+	switch {
+	case !r.plaintextInput && !r.plaintextResult:
+	case policy.PlaintextRetention == 0:
+		r.redact(input, result)
+	case policy.PlaintextRetention > 0:
+		r.plaintextExpiry = time.Now().Add(policy.PlaintextRetention)
+		time.AfterFunc(policy.PlaintextRetention, expireHistories)
+	}
+}
+
+// expire redacts the plaintext once it has expired.
+func (r *retention) expire(now time.Time, input, result *string) {
+	if !r.plaintextExpiry.IsZero() && !now.Before(r.plaintextExpiry) {
+		r.redact(input, result)
+	}
+}
+
+// redact replaces the plaintext sides of the entry with a placeholder.
+func (r *retention) redact(input, result *string) {
+	if r.plaintextInput {
+		*input = redactedText
+	}
+	if r.plaintextResult {
+		*result = redactedText
+	}
+	*r = retention{}
+}
+
+// expireHistories redacts the expired plaintext of both histories.
+func expireHistories() {
+	now := time.Now()
+	historyMutex.Lock()
+	expireHistory(now)
+	historyMutex.Unlock()
+	cypherHistoryMutex.Lock()
+	expireCypherHistory(now)
+	cypherHistoryMutex.Unlock()
+}
+
+// hasCypherStep reports whether the pipeline encrypts or decrypts, its input and result count as plaintext then.
+func hasCypherStep(pipeline functions.Pipeline) bool {
+	for _, step := range pipeline.Steps {
+		if step == xor || step == rot13 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withPolicy runs the test with the policy and empty histories, restoring both afterwards.
+func withPolicy(t *testing.T, policy HistoryPolicy) {
+	t.Helper()
+	saved := CypherHistoryPolicy
+	CypherHistoryPolicy = policy
+	historyMutex.Lock()
+	history = nil
+	historyMutex.Unlock()
+	cypherHistoryMutex.Lock()
+	cypherHistory = nil
+	cypherHistoryMutex.Unlock()
+	t.Cleanup(func() { CypherHistoryPolicy = saved })
+}
+
+func TestKeyFingerprint(t *testing.T) {
+	policy := HistoryPolicy{KeyMode: KeyFingerprint}
+	fingerprint := policy.keyFingerprint("secret")
+	if want := len("sha256:") + 2*8; len(fingerprint) != want || !strings.HasPrefix(fingerprint, "sha256:") {
+		t.Errorf("keyFingerprint = %q, want sha256: and 8 bytes of hex", fingerprint)
+	}
+	if strings.Contains(fingerprint, "secret") {
+		t.Errorf("keyFingerprint = %q contains the key", fingerprint)
+	}
+}
+
+func TestHistoryRedactsPlaintext(t *testing.T) {
+	tests := []struct {
+		name		string
+		save		func()
+		wantInput	bool // the input is kept
+		wantResult	bool // the result is kept
+	}{
+		{"xor encrypt", func() {
+			saveCypherHistory(xor, "key", "plaintext", "cyphertext", retention{plaintextInput: true})
+		}, false, true},
+		{"xor decrypt", func() {
+			saveCypherHistory(xor, "key", "cyphertext", "plaintext", retention{plaintextResult: true})
+		}, true, false},
+		{"rot13", func() {
+			saveCypherHistory(rot13, "", "plaintext", "cnvagrkg", retention{plaintextInput: true, plaintextResult: true})
+		}, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withPolicy(t, HistoryPolicy{KeyMode: KeyFingerprint})
+			test.save()
+			entry := cypherHistorySnapshot()[0]
+			if (entry.Input != redactedText) != test.wantInput {
+				t.Errorf("input = %q", entry.Input)
+			}
+			if (entry.Result != redactedText) != test.wantResult {
+				t.Errorf("result = %q", entry.Result)
+			}
+		})
+	}
+}
+
+func TestPipelineHistoryFollowsPolicy(t *testing.T) {
+	tests := []struct {
+		pipeline	string
+		redacted	bool
+	}{
+		{"encode", false},
+		{"encode,xor,base64", true},
+		{"scramble", true},
+	}
+	for _, test := range tests {
+		t.Run(test.pipeline, func(t *testing.T) {
+			withPolicy(t, HistoryPolicy{KeyMode: KeyFingerprint})
+			code, resp := postPipeline(t, pipelineRequest{Pipeline: test.pipeline, Key: "key", Input: "aaaa"})
+			if code != http.StatusOK {
+				t.Fatalf("status %d: %s", code, resp.Error)
+			}
+			entry := historySnapshot()[0]
+			redacted := entry.Input == redactedText && entry.Result == redactedText
+			if redacted != test.redacted {
+				t.Errorf("history entry %q → %q, want redacted %v", entry.Input, entry.Result, test.redacted)
+			}
+		})
+	}
+}
+
+func TestHistoryExpiresOnWrite(t *testing.T) {
+	withPolicy(t, HistoryPolicy{KeyMode: KeyFingerprint, PlaintextRetention: time.Hour})
+	saveCypherHistory(rot13, "", "plaintext", "cnvagrkg", retention{plaintextInput: true, plaintextResult: true})
+	savePlaintextHistory("pipeline scramble", "plaintext", "cnvagrkg", retention{plaintextInput: true, plaintextResult: true})
+
+	// move the expiry into the past, the next write must redact the entries without a snapshot.
+	cypherHistoryMutex.Lock()
+	cypherHistory[0].plaintextExpiry = time.Now().Add(-time.Second)
+	cypherHistoryMutex.Unlock()
+	historyMutex.Lock()
+	history[0].plaintextExpiry = time.Now().Add(-time.Second)
+	historyMutex.Unlock()
+
+	saveCypherHistory(xor, "key", "cyphertext", "plaintext", retention{plaintextResult: true})
+	saveHistory("encode", "aaaa", "[4 a]")
+
+	// entries are stored newest first.
+	cypherHistoryMutex.Lock()
+	expired := cypherHistory[1]
+	cypherHistoryMutex.Unlock()
+	if expired.Input != redactedText || expired.Result != redactedText {
+		t.Errorf("cypher history kept expired plaintext %q → %q", expired.Input, expired.Result)
+	}
+	historyMutex.Lock()
+	expiredArt := history[1]
+	historyMutex.Unlock()
+	if expiredArt.Input != redactedText || expiredArt.Result != redactedText {
+		t.Errorf("art history kept expired plaintext %q → %q", expiredArt.Input, expiredArt.Result)
+	}
+
+	// the new entry is still within its retention.
+	if latest := cypherHistorySnapshot()[0]; latest.Result != "plaintext" {
+		t.Errorf("plaintext within the retention was redacted: %q", latest.Result)
+	}
+}
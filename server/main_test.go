@@ -0,0 +1,29 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// the handlers render error pages with the template of the web interface.
+	if err := LoadTemplate("../public/index.html"); err != nil {
+		log.Fatal(err)
+	}
+	ArtGallery = NewGallery("../resources")
+	os.Exit(m.Run())
+}
+
+// postForm sends the form values to the handler and returns the response.
+func postForm(handler http.HandlerFunc, target string, form url.Values) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
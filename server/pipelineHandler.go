@@ -0,0 +1,173 @@
+package server
+
+import (
+	"art/functions"
+	"errors"
+	"log"
+	"net/http"
+	"unicode/utf8"
+)
+
+// limits for pipelines, so a long chain of growing steps can't be used for abuse.
+const (
+	maxPipelineSteps		= 10
+	maxPipelineDataLength	= 4 * MaxInputLength // max size of the data passed between steps
+)
+
+// pipelineRequest holds the inputs of a pipeline run, shared by the form and the JSON API.
+type pipelineRequest struct {
+	Pipeline	string `json:"pipeline"`	// recipe name or step list, e.g. "encode,xor,base64"
+	Key			string `json:"key"`		// key for xor steps
+	Input		string `json:"input"`
+	Invert		bool   `json:"invert"`	// runs the inverse of the pipeline
+}
+
+// pipelineResponse is the JSON body returned by PipelineAPIHandler.
+type pipelineResponse struct {
+	Pipeline	string `json:"pipeline,omitempty"` // the steps that were actually run
+	Result		string `json:"result,omitempty"`
+	Error		string `json:"error,omitempty"`
+}
+
+/*
+	PipelineHandler handles /pipeline POST requests.
+		- expects 'pipeline', 'input', optional 'key' and 'invert' form values.
+		- runs every step of the pipeline in order (or the inverse pipeline).
+		- records the whole chain in the history.
+		- renders the result or error status on the pipeline tab.
+*/
+func PipelineHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		log.Printf("pipelineHandler: Method Not Allowed: received %s, only POST allowed", r.Method)
+		http.Error(w, MsgMethodNotAllowed, http.StatusMethodNotAllowed)
+		return
+	}
+	// prepare data struct for rendering template response
+	data := CombinedPageData{
+		Section: "pipeline",
+	}
+
+	req := pipelineRequest{
+		Pipeline:	r.FormValue("pipeline"),
+		Key:		r.FormValue("key"),
+		Input:		normalizeNewLines(r.FormValue("input")),
+		Invert:		r.FormValue("invert") != "",
+	}
+
+	// prepopulate the form fields for response rendering
+	data.Pipeline = req.Pipeline
+	data.Invert = req.Invert
+	data.PipelineInput = req.Input
+	data.LineCount = countLines(req.Input)
+
+	result, chain, code, errMsg := processPipeline(&req)
+	if errMsg != "" {
+		respondWithError(w, code, errMsg, &data)
+		return
+	}
+
+	data.PipelineResult = result
+	data.StatusCode = http.StatusOK
+	data.StatusType = statusSuccess
+	data.StatusMessage = formatStatusMessage(http.StatusOK, "pipeline completed: "+chain)
+	data.LineCount = max(countLines(req.Input), countLines(result))
+
+	// copy the history, expired plaintext is redacted first.
+	data.History = historySnapshot()
+
+	renderTemplate(w, data)
+}
+
+/*
+	PipelineAPIHandler is the JSON version of PipelineHandler.
+		- expects POST with a JSON body: {"pipeline", "key", "input", "invert"}.
+		- responds with {"pipeline", "result"} or {"error"} and a matching status code.
+*/
+func PipelineAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, pipelineResponse{Error: MsgMethodNotAllowed})
+		return
+	}
+
+	var req pipelineRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		log.Printf("pipelineAPIHandler: %v", err)
+		writeJSON(w, http.StatusBadRequest, pipelineResponse{Error: MsgInvalidJSON})
+		return
+	}
+	req.Input = normalizeNewLines(req.Input)
+
+	result, chain, code, errMsg := processPipeline(&req)
+	if errMsg != "" {
+		writeJSON(w, code, pipelineResponse{Error: errMsg})
+		return
+	}
+	writeJSON(w, http.StatusOK, pipelineResponse{Pipeline: chain, Result: result})
+}
+
+/*
+	processPipeline validates the request and runs the pipeline.
+	- returns the result and the chain of steps that was run.
+	- on failure returns the HTTP status code and message to show to the user.
+	- successful runs are saved in the history with the whole chain as the action.
+*/
+func processPipeline(req *pipelineRequest) (string, string, int, string) {
+	if req.Input == "" {
+		return "", "", http.StatusBadRequest, MsgInputEmpty
+	}
+	if inputExceedsLimit(req.Input, MaxInputLength) {
+		log.Printf("pipelineHandler: Input too long: %d characters", len(req.Input))
+		return "", "", http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgInputTooLong)
+	}
+	if inputExceedsLimit(req.Key, maxKeyLength) {
+		return "", "", http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, "XOR key is too long")
+	}
+
+	pipeline, err := functions.ParsePipeline(req.Pipeline)
+	if err == nil && req.Invert {
+		pipeline, err = pipeline.Inverse()
+	}
+	if err != nil {
+		return "", "", http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, err.Error())
+	}
+	if len(pipeline.Steps) > maxPipelineSteps {
+		return "", "", http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgTooManySteps)
+	}
+
+	opts := functions.PipelineOptions{
+		Key:		[]byte(req.Key),
+		MultiLine:	true,
+		BeforeStep:	checkPipelineStep,
+	}
+	result, err := pipeline.Run(req.Input, opts)
+	if err != nil {
+		log.Printf("pipelineHandler: %v", err)
+		return "", "", http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, err.Error())
+	}
+	// results from a chain ending in xor are binary and can't be shown as text.
+	if !utf8.ValidString(result) {
+		return "", "", http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, MsgBinaryResult)
+	}
+
+	// one side of a chain with a cypher step is plaintext, which one depends on the chain so both are treated as such.
+	chain := pipeline.String()
+	cypher := hasCypherStep(pipeline)
+	savePlaintextHistory("pipeline "+chain, req.Input, result, retention{plaintextInput: cypher, plaintextResult: cypher})
+	return result, chain, http.StatusOK, ""
+}
+
+// checkPipelineStep enforces the size limits before every step of a pipeline.
+func checkPipelineStep(step, input string) error {
+	if inputExceedsLimit(input, maxPipelineDataLength) {
+		return errors.New(MsgInputTooLong)
+	}
+	if step == "decode" && decodedExceedsLimit(input, MaxInputLength) {
+		return errors.New(MsgResultTooLong)
+	}
+	// transforms like scale and pad grow the art, their result is projected before they run.
+	if t, err := functions.ParseTransform(step); err == nil &&
+		functions.CheckTransforms(input, []functions.Transform{t}, maxPipelineDataLength) != nil {
+		return errors.New(MsgResultTooLong)
+	}
+	return nil
+}
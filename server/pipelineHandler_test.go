@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// postPipeline sends the request to the JSON API and returns the status code and response.
+func postPipeline(t *testing.T, req pipelineRequest) (int, pipelineResponse) {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	PipelineAPIHandler(w, httptest.NewRequest(http.MethodPost, "/api/pipeline", strings.NewReader(string(body))))
+	var resp pipelineResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, resp
+}
+
+func TestPipelineTransformLimits(t *testing.T) {
+	art := strings.Repeat(strings.Repeat("#", 20)+"\n", 40)
+	tests := []struct {
+		pipeline	string
+		want		int
+	}{
+		{"mirror,scale:2", http.StatusOK},
+		{"scale:8,scale:8,scale:8", http.StatusUnprocessableEntity},
+		{"pad:1000", http.StatusUnprocessableEntity},
+		{"pad:2000000000", http.StatusBadRequest},
+		{"crop:1x40,rotate", http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.pipeline, func(t *testing.T) {
+			code, resp := postPipeline(t, pipelineRequest{Pipeline: test.pipeline, Input: art})
+			if code != test.want {
+				t.Errorf("status %d, want %d: %s", code, test.want, resp.Error)
+			}
+		})
+	}
+}
+
+// counts are digits only, a signed count must not slip past the decoded size check.
+func TestPipelineDecodeLimits(t *testing.T) {
+	tests := []struct {
+		input	string
+		want	int
+	}{
+		{"[50 x]", http.StatusOK},
+		{"[50000000 x]", http.StatusUnprocessableEntity},
+		{"[+50000000 x]", http.StatusUnprocessableEntity},
+		{"[-5 x]", http.StatusUnprocessableEntity},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			code, resp := postPipeline(t, pipelineRequest{Pipeline: "decode", Input: test.input})
+			if code != test.want {
+				t.Errorf("status %d, want %d: %s", code, test.want, resp.Error)
+			}
+			if len(resp.Result) > MaxInputLength {
+				t.Errorf("result of %d bytes", len(resp.Result))
+			}
+		})
+	}
+}
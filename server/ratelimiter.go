@@ -3,6 +3,7 @@ package server
 import (
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 	"net"
@@ -66,7 +67,10 @@ func (limiter *RateLimiter) Middleware(next http.Handler) http.Handler {
 		}
 
 		if !limiter.allow(ip) {
-			if r.Method == http.MethodPost {
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				// JSON API clients get a JSON error body instead of the page.
+				writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "429 too many requests"})
+			} else if r.Method == http.MethodPost {
 				// Render error page via template for POST requests.
 				data := CombinedPageData{
 					Section:        "art",
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"art/functions"
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const maxRenderPadding = 200 // max padding in pixels, keeps rendered images small
+
+/*
+	RenderHandler handles /render POST requests and sends the art as a downloadable image.
+		- expects 'art' (decoded art text) and 'format' (png, svg or gif) form values.
+		- gif plays animated art (with the 'animation' form value), still art becomes a single frame.
+		- optional 'fg' and 'bg' colours (#rrggbb) and 'padding' in pixels.
+		- errors are sent as plain text, since the response is a download.
+		  art too large to render shows the decoder page with a 413 instead, so the art isn't lost.
+*/
+func RenderHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, MsgMethodNotAllowed, http.StatusMethodNotAllowed)
+		return
+	}
+
+	art := normalizeNewLines(r.FormValue("art"))
+	if art == "" {
+		http.Error(w, MsgInputEmpty, http.StatusBadRequest)
+		return
+	}
+	if inputExceedsLimit(art, MaxInputLength) {
+		http.Error(w, MsgInputTooLong, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	opts := functions.DefaultRenderOptions()
+	var err error
+	if fg := r.FormValue("fg"); fg != "" {
+		if opts.Foreground, err = functions.ParseColor(fg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if bg := r.FormValue("bg"); bg != "" {
+		if opts.Background, err = functions.ParseColor(bg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if padding := r.FormValue("padding"); padding != "" {
+		opts.Padding, err = strconv.Atoi(padding)
+		if err != nil || opts.Padding < 0 || opts.Padding > maxRenderPadding {
+			http.Error(w, "padding must be a number between 0 and 200", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	var contentType string
+	switch format := r.FormValue("format"); format {
+	case "png":
+		err = functions.RenderPNG(&buf, art, opts)
+		contentType = "image/png"
+	case "svg":
+		err = functions.RenderSVG(&buf, art, opts)
+		contentType = "image/svg+xml"
+	case "gif":
+		animation := functions.Animation{Frames: []functions.Frame{{Art: art, Delay: functions.DefaultFrameDelay}}}
+		if r.FormValue("animation") != "" {
+			if animation, err = functions.SplitFrames(art); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		err = functions.WriteGIF(&buf, animation, opts)
+		contentType = "image/gif"
+	default:
+		http.Error(w, "format must be png, svg or gif", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, functions.ErrImageTooLarge) {
+		data := CombinedPageData{Section: "decoder", EncodeInput: art, LineCount: countLines(art)}
+		respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgRenderTooLarge), &data)
+		return
+	}
+	if err != nil {
+		log.Printf("renderHandler: %v", err)
+		http.Error(w, MsgInternalServerError, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\"art."+r.FormValue("format")+"\"")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRenderHandlerLimits(t *testing.T) {
+	tests := []struct {
+		name	string
+		art		string
+		format	string
+		want	int
+	}{
+		{"small png", "#-#\n-#-", "png", http.StatusOK},
+		{"small svg", "#-#\n-#-", "svg", http.StatusOK},
+		{"small gif", "#-#\n-#-", "gif", http.StatusOK},
+		{"long line and many rows", strings.Repeat("#", 5000) + strings.Repeat("\n", 4999), "png", http.StatusRequestEntityTooLarge},
+		{"too many columns", strings.Repeat("#", 1001), "svg", http.StatusRequestEntityTooLarge},
+		{"too many rows", strings.Repeat("#\n", 1001), "gif", http.StatusRequestEntityTooLarge},
+		{"wide but within the pixels", strings.Repeat(strings.Repeat("#", 900)+"\n", 9), "png", http.StatusOK},
+		{"too many pixels", strings.Repeat(strings.Repeat("#", 999)+"\n", 9) + strings.Repeat("\n", 500), "png", http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := postForm(RenderHandler, "/render", url.Values{"art": {test.art}, "format": {test.format}})
+			if w.Code != test.want {
+				t.Errorf("status %d, want %d: %s", w.Code, test.want, firstLine(w.Body.String()))
+			}
+		})
+	}
+}
+
+// firstLine shortens a response body for error messages.
+func firstLine(body string) string {
+	line, _, _ := strings.Cut(body, "\n")
+	return line
+}
+
+func TestRenderHandlerAnimation(t *testing.T) {
+	tests := []struct {
+		name		string
+		art			string
+		animation	string
+		want		int
+	}{
+		{"frames", "@frame\n-\n@frame\n=", "1", http.StatusOK},
+		{"marker lines as text", "@frame\n-\n@frame\n=", "", http.StatusOK},
+		{"invalid frames", "text\n@frame\n-", "1", http.StatusBadRequest},
+		{"large canvas", "@frame\n" + strings.Repeat("#", 1000) + "\n@frame" + strings.Repeat("\n", 999), "1", http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := postForm(RenderHandler, "/render", url.Values{"art": {test.art}, "format": {"gif"}, "animation": {test.animation}})
+			if w.Code != test.want {
+				t.Errorf("status %d, want %d: %s", w.Code, test.want, firstLine(w.Body.String()))
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"art/functions"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"unicode/utf8"
+)
+
+const maxUploadSize = 1 << 20 // max size of an uploaded file
+
+/*
+	UnpackHandler handles /unpack POST requests with an uploaded file.
+		- expects multipart form data with a 'file' and an optional 'key' (for XOR'd bundles).
+		- art bundles are checked and every transform is reversed, the art is shown in the result field.
+		- any other text file is loaded into the decode field as is.
+		- unpacked bundles are saved in the history with their chain of transforms.
+*/
+func UnpackHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		log.Printf("unpackHandler: Method Not Allowed: received %s, only POST allowed", r.Method)
+		http.Error(w, MsgMethodNotAllowed, http.StatusMethodNotAllowed)
+		return
+	}
+	data := CombinedPageData{
+		Section: "decoder",
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	file, header, err := r.FormFile("file")
+	// the form is parsed while reading the body, so an oversized upload already fails here.
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgFileTooLarge), &data)
+		return
+	}
+	if err != nil {
+		log.Printf("unpackHandler: %s: %v", MsgFailedToParseForm, err)
+		respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgNoFileUploaded), &data)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, MsgFileTooLarge), &data)
+			return
+		}
+		log.Printf("unpackHandler: error reading upload: %v", err)
+		respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, MsgNoFileUploaded), &data)
+		return
+	}
+
+	// plain or encoded art: load it into the decoder so the user can pick what to do.
+	if !functions.IsBundle(content) {
+		text := normalizeNewLines(string(content))
+		if !utf8.ValidString(text) || inputExceedsLimit(text, MaxInputLength) {
+			respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, MsgNotTextFile), &data)
+			return
+		}
+		data.DecodeInput = text
+		data.StatusCode = http.StatusOK
+		data.StatusType = StatusInfo
+		data.StatusMessage = formatStatusMessage(http.StatusOK, "file is not an art bundle, loaded as text")
+		data.LineCount = countLines(text)
+		renderTemplate(w, data)
+		return
+	}
+
+	bundle, err := functions.ParseBundle(content)
+	if err != nil {
+		respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, err.Error()), &data)
+		return
+	}
+	key := r.FormValue("key")
+	if inputExceedsLimit(key, maxKeyLength) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, formatStatusMessage(http.StatusRequestEntityTooLarge, "XOR key is too long"), &data)
+		return
+	}
+	if bundle.NeedsKey() && key == "" {
+		respondWithError(w, http.StatusBadRequest, formatStatusMessage(http.StatusBadRequest, "bundle is XOR encrypted, "+MsgXOREmpty), &data)
+		return
+	}
+
+	result, err := bundle.Unpack(functions.PipelineOptions{Key: []byte(key), BeforeStep: checkPipelineStep})
+	if err != nil {
+		respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, err.Error()), &data)
+		return
+	}
+	if !utf8.ValidString(result) {
+		respondWithError(w, http.StatusUnprocessableEntity, formatStatusMessage(http.StatusUnprocessableEntity, MsgNotTextFile), &data)
+		return
+	}
+
+	// the input is the file name, the result of a bundle with a cypher step is plaintext.
+	chain := bundle.Pipeline().String()
+	savePlaintextHistory("unpack "+chain, header.Filename, result, retention{plaintextResult: hasCypherStep(bundle.Pipeline())})
+
+	data.EncodeInput = result
+	data.StatusCode = http.StatusOK
+	data.StatusType = statusSuccess
+	data.StatusMessage = formatStatusMessage(http.StatusOK, "unpacked bundle: "+chain)
+	data.LineCount = countLines(result)
+
+	data.History = historySnapshot()
+
+	renderTemplate(w, data)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"art/functions"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// uploadFile posts the file and the key to UnpackHandler.
+func uploadFile(t *testing.T, content []byte, key string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "art.bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(content)
+	form.WriteField("key", key)
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/unpack", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	UnpackHandler(w, r)
+	return w
+}
+
+func TestUnpackHandler(t *testing.T) {
+	pipeline, err := functions.ParsePipeline("seal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := functions.PackBundle("#####", pipeline, functions.PipelineOptions{Key: []byte("secret"), MultiLine: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := bundle.Marshal()
+
+	tests := []struct {
+		name	string
+		content	[]byte
+		key		string
+		want	int
+	}{
+		{"sealed bundle", sealed, "secret", http.StatusOK},
+		{"plain text", []byte("[5 #]"), "", http.StatusOK},
+		{"missing key", sealed, "", http.StatusBadRequest},
+		{"wrong key", sealed, "wrong", http.StatusUnprocessableEntity},
+		{"corrupted bundle", append(bytes.Clone(sealed), '!'), "secret", http.StatusUnprocessableEntity},
+		{"binary file", []byte{0xff, 0xfe, 0x00}, "", http.StatusUnprocessableEntity},
+		{"too large", bytes.Repeat([]byte("#"), maxUploadSize+1), "", http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if w := uploadFile(t, test.content, test.key); w.Code != test.want {
+				t.Errorf("status %d, want %d", w.Code, test.want)
+			}
+		})
+	}
+}
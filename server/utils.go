@@ -3,12 +3,12 @@ package server
 // helper functions for data validation and template rendering
 
 import (
+	"art/functions"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
 	"log"
-	"regexp"
-	"strconv"
 	"strings"
 )
 /* Predefined messages and constants for error handling and status reporting.
@@ -27,6 +27,20 @@ const (
 	MsgSuccessfullyDecoded	= "successfully decoded"
 	MsgInternalServerError 	= "internal server error"
 	MsgMethodNotAllowed 	= "method not allowed"
+	MsgInvalidJSON			= "request body must be valid JSON"
+	MsgRawEncoding			= "raw encoding is only available for file output, choose a text encoding"
+	MsgTooManySteps			= "pipeline has too many steps, maximum is 10"
+	MsgBinaryResult			= "pipeline result is binary, end it with an encoding step like base64"
+	MsgNoFileUploaded		= "no file uploaded"
+	MsgFileTooLarge			= "file is too large, maximum size is 1 MB"
+	MsgImageTooLarge		= "image is too large, maximum size is 8 MB"
+	MsgRenderTooLarge		= "art is too large to render, maximum is 1000 columns, 1000 rows and 16 megapixels"
+	MsgDiffTooLarge			= "art is too large to compare, maximum is 1000 columns, 1000 rows and 100,000 cells"
+	MsgInvalidWidth			= "width must be a number between 1 and 120"
+	MsgBannerTooLong		= "banner text is too long, maximum length is 200 characters"
+	MsgUnknownFont			= "unknown font"
+	MsgNotTextFile			= "file is not a bundle and not a text file within the 10,000 character limit"
+	MsgGalleryUnavailable	= "gallery is unavailable"
 
 	StatusInfo 				= "info"
 	StatusError				= "error"
@@ -37,17 +51,28 @@ const (
 	MaxReturnLength 	= 1000
 	MaxHistoryEntries 	= 20
 	maxKeyLength 		= 256 // max length for XOR key
+	maxJSONBodySize		= 1 << 20 // max size of a JSON API request body
 )
 
-// parse and store the main HTML template used for rendering pages.
-var tmpl = template.Must(template.ParseFiles("public/index.html"))
+// main HTML template used for rendering pages, parsed by LoadTemplate.
+var tmpl *template.Template
+
+// LoadTemplate parses the main HTML template, it must be called before serving requests.
+func LoadTemplate(path string) error {
+	t, err := template.ParseFiles(path)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %w", err)
+	}
+	tmpl = t
+	return nil
+}
 
 /* 
 	CombinedPageData holds all the dynamic data passed into the HTML template.
-	it covers both "art" and "cypher" sections of the app.
+	it covers the "art", "cypher", "pipeline" and "banner" sections of the app.
 */
 type CombinedPageData struct {
-	Section			string // specifies whether we're in 'art' or 'cypher' section
+	Section			string // specifies whether we're in 'art', 'cypher', 'pipeline' or 'banner' section
 
 	// fields for the art encoder/decoder page
 	DecodeInput		string
@@ -56,6 +81,9 @@ type CombinedPageData struct {
 	StatusType		string
 	StatusMessage	string
 	LineCount		int
+	StripANSI		bool // colour escape sequences are removed from the result
+	Animation		bool // the art is an animation, its frames are separated by marker lines
+	Transforms		string // transforms applied to the art, e.g. "mirror,scale:2"
 	History			[]HistoryEntry
 
 	// fields for the cypher page
@@ -63,8 +91,99 @@ type CombinedPageData struct {
 	Input			string
 	Result			string
 	Key				string
+	Encoding		string // cyphertext encoding used for XOR
+	Direction		string // XOR direction: encrypt, decrypt or auto
+	Sensitive		bool   // operation is kept out of the history
 	CypherHistory	[]CypherHistoryEntry
+
+	// fields for the pipeline page
+	Pipeline		string
+	Invert			bool
+	PipelineInput	string
+	PipelineResult	string
+
+	// fields for the banner page
+	BannerText		string
+	BannerFont		string
+	BannerLayout	string
+	BannerEncode	bool
+	BannerResult	string
+	DiffOld			string
+	DiffNew			string
+	DiffRows		[]functions.DiffRow // both versions side by side, empty until they are compared
+	DiffUnified		string
+}
+
+// Encodings returns the cyphertext encodings selectable in the web form (raw is file only).
+func (CombinedPageData) Encodings() []string {
+	var encodings []string
+	for _, encoding := range functions.Encodings {
+		if encoding != functions.EncodingRaw {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return encodings
+}
+// ColourPreview returns the decoded art as HTML with its colours, empty when the art has no colours.
+func (d CombinedPageData) ColourPreview() template.HTML {
+	if !functions.HasANSI(d.EncodeInput) {
+		return ""
+	}
+	// ANSIToHTML escapes all of the text, only its own <span> elements are HTML.
+	return template.HTML(functions.ANSIToHTML(d.EncodeInput))
+}
+
+// animationFrame is a frame of the animation preview, the delay is in milliseconds.
+type animationFrame struct {
+	Art		string `json:"art"`
+	Delay	int64  `json:"delay"`
 }
+
+// AnimationFrames returns the frames of decoded animated art for the preview, nil for still art.
+func (d CombinedPageData) AnimationFrames() []animationFrame {
+	if !d.Animation {
+		return nil
+	}
+	animation, err := functions.SplitFrames(d.EncodeInput)
+	if err != nil {
+		return nil
+	}
+	frames := make([]animationFrame, len(animation.Frames))
+	for i, frame := range animation.Frames {
+		frames[i] = animationFrame{Art: functions.StripANSI(frame.Art), Delay: frame.Delay.Milliseconds()}
+	}
+	return frames
+}
+
+// Recipes returns the built in pipeline recipe names for the pipeline form.
+func (CombinedPageData) Recipes() []string {
+	return functions.RecipeNames()
+}
+
+// PipelineSteps returns every pipeline step name for the pipeline form.
+func (CombinedPageData) PipelineSteps() []string {
+	return functions.PipelineStepNames()
+}
+
+// TransformNames returns the transforms with their arguments for the decoder and pipeline forms.
+func (CombinedPageData) TransformNames() []string {
+	return functions.TransformNames
+}
+
+// GalleryPieces returns the pieces of the art gallery, empty when the directory can't be read.
+func (CombinedPageData) GalleryPieces() []GalleryPiece {
+	pieces, err := ArtGallery.Pieces()
+	if err != nil {
+		log.Printf("gallery: %v", err)
+	}
+	return pieces
+}
+
+// Fonts returns the bundled FIGlet font names for the banner form.
+func (CombinedPageData) Fonts() []string {
+	return functions.FontNames()
+}
+
 // normalizeNewLines converts windows-style CRLF line endings ("\r\n")
 // to Unix-style LF("\n") for consistent text processing.
 func normalizeNewLines(s string) string {
@@ -104,46 +223,14 @@ func max(a, b int) int {
 	return b
 }
 
-// decodedExceedsLimits estimates the length of the decoded text for
-// inputs that use the custom compression format like '[count pattern]'
-// Returns true if the decoded length would exceed the specified limit.
-// This helps avoid decoding very large inputs that could cause performance issues.
+// decodedExceedsLimit reports whether the decoded text would be longer than limit.
+// the size is measured with functions.InspectArt, which uses the decoder's own tokenizer without expanding the runs,
+// so every count the decoder accepts is counted. malformed input is left to the decoder, which reports the position.
 func decodedExceedsLimit(input string, limit int) bool {
-	pattern := regexp.MustCompile(`\[(\d+)\s([^\[\]]+)\]`)
-	matches := pattern.FindAllStringSubmatchIndex(input, -1)
-
-	decodedLen := 0
-	prevEnd := 0
-
-	for _, match := range matches {
-		start, end := match[0], match[1]		// overall match indices
-		countStr := input[match[2]:match[3]]	//repetition count as string
-		patternStr := input[match[4]:match[5]]	// pattern to repeat
-
-		// add length of literal text before this encoded chunk
-		decodedLen += len(input[prevEnd:start])
-
-		count, err := strconv.Atoi(countStr)	// convert count string to int
-		if err != nil {
-			continue // skip if count is invalid
-		}
-		decodedLen += count * len(patternStr)	// add length of repeated pattern * count
-
-		// too long, return true.
-		if decodedLen > limit {					
-			return true
-		}
-
-		prevEnd = end 	// move past this match
-		
-
-	}
-
-	// add any remaining literal text after the last encoded chunk
-	decodedLen += len(input[prevEnd:])
-
-	return decodedLen > limit
+	stats, err := functions.InspectArt(input)
+	return err == nil && stats.DecodedSize > int64(limit)
 }
+
 // inputExceedsLimit checks if the raw input string exceeds the maximum allowed length.
 func inputExceedsLimit(input string, limit int) bool {
 	return len(input) > limit
@@ -156,4 +243,23 @@ func renderTemplate(w http.ResponseWriter, data CombinedPageData) {
 		log.Printf("Template execution error: %v", err)
 		http.Error(w, "Failed to render template", http.StatusInternalServerError)
 	}
-}
\ No newline at end of file
+}
+
+// writeJSON encodes v as the JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("JSON encoding error: %v", err)
+	}
+}
+
+// decodeJSONBody decodes a size limited JSON request body into v, unknown fields are rejected.
+func decodeJSONBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxJSONBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("error decoding JSON body: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package server
+
+import "testing"
+
+func TestDecodedExceedsLimit(t *testing.T) {
+	tests := []struct {
+		input	string
+		limit	int
+		want	bool
+	}{
+		{"[10 x]", 10, false},
+		{"[11 x]", 10, true},
+		{"[5 ab]", 10, false},
+		{"[6 ab]", 10, true},
+		{"[5 x]\n[5 y]", 10, true}, // the line ending counts
+		{"[3 ═]", 9, false},        // bytes, not characters
+		{"[4 ═]", 9, true},
+		{"[9223372036854775807 x][9223372036854775807 x]", 10, true}, // saturates instead of overflowing
+		{"plain text longer than ten", 10, true},
+		{"[+5 x]", 10, false}, // malformed, left to the decoder
+	}
+	for _, test := range tests {
+		if got := decodedExceedsLimit(test.input, test.limit); got != test.want {
+			t.Errorf("decodedExceedsLimit(%q, %d) = %v, want %v", test.input, test.limit, got, test.want)
+		}
+	}
+}